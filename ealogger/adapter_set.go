@@ -0,0 +1,252 @@
+package ealogger
+
+import (
+	"errors"
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/adapters"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// adapterSet is an immutable snapshot of the adapters of a Logger and the settings
+// applied with them, so that a log never sees new adapters with old settings.
+type adapterSet struct {
+	// mu is held for reading while a log is written to the adapters, so that
+	// replaced adapters can be drained before they are closed.
+	mu      sync.RWMutex
+	retired bool
+
+	// names holds the unique name of each adapter.
+	names    []string
+	adapters []adapters.Adapter
+
+	settings
+}
+
+// settings are the options of a Logger that are applied to every log.
+type settings struct {
+	// levels holds the per-name level overrides, see SetLevels.
+	levels *LevelSpec
+	// base holds the fields added to every log, see SetBaseFields.
+	base []shared.Field
+
+	stacktraceLevel shared.Level
+	redactor        *Redactor
+	sampler         *Sampler
+}
+
+// with returns a set holding the same adapters and settings, for changes of the settings.
+func (s *adapterSet) with() *adapterSet {
+	return &adapterSet{
+		names:    append([]string(nil), s.names...),
+		adapters: append([]adapters.Adapter(nil), s.adapters...),
+		settings: s.settings,
+	}
+}
+
+// isEnabled reports whether at least one adapter accepts logs of the given level.
+func (s *adapterSet) isEnabled(level shared.Level) bool {
+	for _, adapter := range s.adapters {
+		enabler, ok := adapter.(adapters.LevelEnabler)
+		if !ok || enabler.IsEnabled(level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNameEnabled is isEnabled taking the level overrides into account.
+func (s *adapterSet) isNameEnabled(name string, level shared.Level) bool {
+	if !s.isEnabled(level) {
+		return false
+	}
+
+	return s.levels == nil || s.levels.Level(name).IsEnabled(level)
+}
+
+func (s *adapterSet) add(name string, adapter adapters.Adapter) {
+	s.names = append(s.names, name)
+	s.adapters = append(s.adapters, adapter)
+}
+
+// get returns the adapter named name, nil if there is none.
+func (s *adapterSet) get(name string) adapters.Adapter {
+	for i, adapterName := range s.names {
+		if adapterName == name {
+			return s.adapters[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *adapterSet) contains(adapter adapters.Adapter) bool {
+	for _, a := range s.adapters {
+		if a == adapter {
+			return true
+		}
+	}
+
+	return false
+}
+
+// snapshot returns the current adapters. The zero Logger has none; its empty set is
+// created on first use.
+func (l *Logger) snapshot() *adapterSet {
+	if set := l.adapters.Load(); set != nil {
+		return set
+	}
+
+	l.adapters.CompareAndSwap(nil, newAdapterSet(nil))
+
+	return l.adapters.Load()
+}
+
+// acquireAdapters returns the current adapters, read-locked until the caller unlocks them.
+func (l *Logger) acquireAdapters() *adapterSet {
+	for {
+		set := l.snapshot()
+
+		set.mu.RLock()
+		if !set.retired {
+			return set
+		}
+		set.mu.RUnlock()
+	}
+}
+
+// swapAdapters replaces the adapters with the set returned by update. Once the logs being
+// written to the old adapters are done, the adapters that were not kept are closed.
+// Nothing is changed if update returns an error.
+func (l *Logger) swapAdapters(update func(old *adapterSet) (*adapterSet, error)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old := l.snapshot()
+	set, err := update(old)
+	if err != nil {
+		return err
+	}
+
+	l.adapters.Store(set)
+
+	old.mu.Lock()
+	old.retired = true
+	old.mu.Unlock()
+
+	var errs []error
+	for _, adapter := range old.adapters {
+		if set.contains(adapter) {
+			continue
+		}
+
+		if closer, ok := adapter.(interface{ Close() error }); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// updateSettings replaces the settings of the logger, keeping its adapters.
+func (l *Logger) updateSettings(update func(s *settings)) {
+	_ = l.swapAdapters(func(old *adapterSet) (*adapterSet, error) {
+		set := old.with()
+		update(&set.settings)
+
+		return set, nil
+	})
+}
+
+// AddAdapter adds an adapter under the given name while the logger is in use.
+// An adapter already registered under that name is replaced, and closed once the
+// logs being written to it are done.
+func (l *Logger) AddAdapter(name string, adapter adapters.Adapter) error {
+	if name == "" {
+		return errors.New("ealogger: adapter name is required")
+	}
+
+	return l.swapAdapters(func(old *adapterSet) (*adapterSet, error) {
+		set := &adapterSet{settings: old.settings}
+		replaced := false
+
+		for i, adapterName := range old.names {
+			if adapterName == name {
+				set.add(name, adapter)
+				replaced = true
+				continue
+			}
+			set.add(adapterName, old.adapters[i])
+		}
+
+		if !replaced {
+			set.add(name, adapter)
+		}
+
+		return set, nil
+	})
+}
+
+// RemoveAdapter removes the adapter with the given name and closes it once the logs
+// being written to it are done.
+func (l *Logger) RemoveAdapter(name string) error {
+	if _, ok := l.Adapter(name); !ok {
+		return fmt.Errorf("ealogger: adapter %q not found", name)
+	}
+
+	return l.swapAdapters(func(old *adapterSet) (*adapterSet, error) {
+		set := &adapterSet{settings: old.settings}
+
+		for i, adapterName := range old.names {
+			if adapterName != name {
+				set.add(adapterName, old.adapters[i])
+			}
+		}
+
+		return set, nil
+	})
+}
+
+// Adapter returns the adapter with the given name.
+func (l *Logger) Adapter(name string) (adapters.Adapter, bool) {
+	adapter := l.snapshot().get(name)
+
+	return adapter, adapter != nil
+}
+
+// Adapters returns the adapters of the logger by name.
+func (l *Logger) Adapters() map[string]adapters.Adapter {
+	set := l.snapshot()
+
+	named := make(map[string]adapters.Adapter, len(set.adapters))
+	for i, name := range set.names {
+		named[name] = set.adapters[i]
+	}
+
+	return named
+}
+
+// newAdapterSet names the adapters passed to NewLogger after their type, such as
+// "console" for a *adapters.ConsoleAdapter, adding a suffix to repeated names.
+func newAdapterSet(list []adapters.Adapter) *adapterSet {
+	set := &adapterSet{settings: settings{stacktraceLevel: shared.UnselectedLevel}}
+
+	for _, adapter := range list {
+		base := strings.ToLower(strings.TrimSuffix(reflect.Indirect(reflect.ValueOf(adapter)).Type().Name(), "Adapter"))
+		if base == "" {
+			base = "adapter"
+		}
+
+		name := base
+		for n := 1; set.get(name) != nil; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		set.add(name, adapter)
+	}
+
+	return set
+}
@@ -0,0 +1,33 @@
+package ealogger
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"testing"
+)
+
+func TestLogger_ZeroValue(t *testing.T) {
+	var l Logger
+
+	l.Info("dropped")
+	l.WithName("db").Warn("dropped")
+
+	if l.IsEnabled(shared.InfoLevel) || l.IsNameEnabled("db", shared.InfoLevel) {
+		t.Fatal("expected a logger without adapters to be disabled")
+	}
+	if _, ok := l.Adapter("console"); ok {
+		t.Fatal("expected no adapters")
+	}
+	if len(l.Adapters()) != 0 || l.Levels() != nil || l.BaseFields() != nil || l.Redactor() != nil {
+		t.Fatal("expected empty settings")
+	}
+
+	capture := &captureAdapter{}
+	if err := l.AddAdapter("capture", capture); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Info("kept")
+	if log := capture.last(t); log.Message != "kept" {
+		t.Fatalf("unexpected message %q", log.Message)
+	}
+}
@@ -4,7 +4,16 @@ import (
 	"github.com/eris-apple/ealogger/ealogger/shared"
 )
 
+// Adapter writes logs to a destination. The Data of a log passed to Log may be reused
+// once Log returns, and its Fields and TypedFields are shared with other adapters,
+// so they must be copied before being modified or retained.
 type Adapter interface {
 	Log(log shared.Log)
 	Format(log *shared.Log)
 }
+
+// LevelEnabler is implemented by adapters that can report whether a log of the given level
+// would be written. Adapters that do not implement it are assumed to accept every level.
+type LevelEnabler interface {
+	IsEnabled(level shared.Level) bool
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	"github.com/eris-apple/ealogger/ealogger/shared"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -22,6 +23,12 @@ type ConsoleConfig struct {
 
 	Level  shared.Level
 	Colors *ConsoleColorConfig
+
+	// Output defaults to os.Stdout.
+	Output io.Writer
+
+	// Sanitize is applied to messages, errors and fields before they are styled. Nil disables it.
+	Sanitize *SanitizePolicy
 }
 
 type ConsoleAdapter struct {
@@ -37,6 +44,8 @@ func (a *ConsoleAdapter) Log(log shared.Log) {
 	a.Format(&log)
 
 	switch log.Level.String() {
+	case shared.TraceLevel.String():
+		a.writer.Log(log.Level.ToCharmbracelet(), log.Data.TraceName+log.Message)
 	case shared.DebugLevel.String():
 		a.writer.Debug(log.Data.TraceName + log.Message)
 	case shared.InfoLevel.String():
@@ -54,18 +63,22 @@ func (a *ConsoleAdapter) Log(log shared.Log) {
 	}
 }
 
+func (a *ConsoleAdapter) IsEnabled(level shared.Level) bool {
+	return a.cfg.Enable && a.cfg.Level.IsEnabled(level)
+}
+
 func (a *ConsoleAdapter) Format(log *shared.Log) {
 	if log.Data.TraceName != "" {
 		log.Data.TraceName = lipgloss.
 			NewStyle().
-			SetString(fmt.Sprintf("[%s]: ", log.Data.TraceName)).
+			SetString(fmt.Sprintf("[%s]: ", a.cfg.Sanitize.Sanitize(log.Data.TraceName))).
 			Foreground(lipgloss.Color(a.cfg.Colors.LevelColors[log.Level])).
 			String()
 	}
 
 	log.Message = lipgloss.
 		NewStyle().
-		SetString(fmt.Sprintf("%s", log.Message)).
+		SetString(a.cfg.Sanitize.SanitizeMessage(log)).
 		Foreground(lipgloss.Color(*a.cfg.Colors.MessageColor)).
 		String()
 
@@ -73,19 +86,29 @@ func (a *ConsoleAdapter) Format(log *shared.Log) {
 		if log.Data.Error != nil {
 			formattedError := lipgloss.
 				NewStyle().
-				SetString(fmt.Sprintf("err=%s", log.Data.Error)).
+				SetString(a.cfg.Sanitize.Sanitize(formatConsoleError(shared.NewErrorInfo(log.Data.Error)))).
 				Foreground(lipgloss.Color(a.cfg.Colors.LevelColors[shared.ErrorLevel])).
 				String()
 
 			log.Message = fmt.Sprintf("%s %s", log.Message, formattedError)
 		}
 
-		if len(log.Data.Fields) > 0 {
+		if len(log.Data.Fields) > 0 || len(log.Data.TypedFields) > 0 {
 			formattedFields := ""
 			for key, field := range log.Data.Fields {
 				formattedField := lipgloss.
 					NewStyle().
-					SetString(fmt.Sprintf("%s=%v ", key, field)).
+					SetString(a.cfg.Sanitize.Sanitize(fmt.Sprintf("%s=%v", key, field)) + " ").
+					Foreground(lipgloss.Color(a.cfg.Colors.LevelColors[log.Level])).
+					String()
+
+				formattedFields += formattedField
+			}
+
+			for _, field := range log.Data.TypedFields {
+				formattedField := lipgloss.
+					NewStyle().
+					SetString(a.cfg.Sanitize.Sanitize(field.Key+"="+field.Text()) + " ").
 					Foreground(lipgloss.Color(a.cfg.Colors.LevelColors[log.Level])).
 					String()
 
@@ -94,9 +117,41 @@ func (a *ConsoleAdapter) Format(log *shared.Log) {
 
 			log.Message = fmt.Sprintf("%s %s", log.Message, formattedFields)
 		}
+
+		if len(log.Data.Stack) > 0 {
+			formattedStack := lipgloss.
+				NewStyle().
+				SetString(log.Data.Stack.String()).
+				Foreground(lipgloss.Color(*a.cfg.Colors.TimestampColor)).
+				String()
+
+			log.Message = fmt.Sprintf("%s\n%s", log.Message, formattedStack)
+		}
 	}
 }
 
+func formatConsoleError(info shared.ErrorInfo) string {
+	var formatted string
+	if len(info.Errors) > 0 {
+		messages := make([]string, len(info.Errors))
+		for i, inner := range info.Errors {
+			messages[i] = inner.Message
+		}
+
+		formatted = fmt.Sprintf("errors=[%s]", strings.Join(messages, "; "))
+	} else {
+		formatted = fmt.Sprintf("err=%s", info.Message)
+	}
+
+	formatted = fmt.Sprintf("%s err.type=%s", formatted, info.Type)
+
+	if len(info.Chain) > 1 {
+		formatted = fmt.Sprintf("%s err.chain=[%s]", formatted, strings.Join(info.Chain[1:], "; "))
+	}
+
+	return formatted
+}
+
 func NewConsoleAdapter(cfg *ConsoleConfig) *ConsoleAdapter {
 	return &ConsoleAdapter{
 		cfg:    cfg,
@@ -128,7 +183,12 @@ func newConsoleLogger(cfg *ConsoleConfig) *log.Logger {
 		return nil
 	}
 
-	logger := log.NewWithOptions(os.Stdout, log.Options{
+	output := cfg.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	logger := log.NewWithOptions(output, log.Options{
 		ReportTimestamp: true,
 		TimeFormat:      time.DateTime,
 		Level:           cfg.Level.ToCharmbracelet(),
@@ -141,9 +201,10 @@ func newConsoleLogger(cfg *ConsoleConfig) *log.Logger {
 
 func defaultConsoleConfig() *ConsoleConfig {
 	return &ConsoleConfig{
-		Enable: true,
-		Level:  shared.DebugLevel,
-		Colors: &ConsoleColorConfig{},
+		Enable:   true,
+		Level:    shared.DebugLevel,
+		Colors:   &ConsoleColorConfig{},
+		Sanitize: NewDefaultSanitizePolicy(),
 	}
 }
 
@@ -178,6 +239,10 @@ func setupDefaultLoggerColors(cfg *ConsoleConfig) {
 		cfg.Colors.LevelColors[shared.InfoLevel] = "#afd7ff"
 	}
 
+	if cfg.Colors.LevelColors[shared.TraceLevel] == "" {
+		cfg.Colors.LevelColors[shared.TraceLevel] = "#6c6c6c"
+	}
+
 	if cfg.Colors.LevelColors[shared.DebugLevel] == "" {
 		cfg.Colors.LevelColors[shared.DebugLevel] = "#969696"
 	}
@@ -0,0 +1,88 @@
+package adapters
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"os"
+)
+
+// ConsoleOptions are the options of "console" adapters in declarative configs.
+type ConsoleOptions struct {
+	// Writer is "stdout", the default, or "stderr".
+	Writer string `json:"writer"`
+	// Sanitize is "escape", the default, "strip", "replace" or "none", see SanitizePolicy.
+	Sanitize string `json:"sanitize"`
+	// AllowNewlines keeps the newlines of multi-line messages, such as DebugJSON output.
+	// They are escaped by default.
+	AllowNewlines bool `json:"allow_newlines"`
+
+	Colors *ConsoleColorOptions `json:"colors"`
+}
+
+type ConsoleColorOptions struct {
+	Timestamp string            `json:"timestamp"`
+	Message   string            `json:"message"`
+	Levels    map[string]string `json:"levels"`
+}
+
+func newConsoleAdapterFromConfig(raw *RawConfig) (Adapter, error) {
+	var options ConsoleOptions
+	if err := raw.Decode(&options); err != nil {
+		return nil, err
+	}
+
+	var errs shared.ConfigErrors
+
+	cfg := &ConsoleConfig{
+		Enable:   true,
+		Level:    raw.Level,
+		Colors:   &ConsoleColorConfig{},
+		Sanitize: sanitizeOption(&errs, raw.Path+".sanitize", options.Sanitize, NewDefaultSanitizePolicy()),
+	}
+	if cfg.Sanitize != nil {
+		cfg.Sanitize.AllowNewlines = options.AllowNewlines
+	}
+
+	switch options.Writer {
+	case "", "stdout":
+	case "stderr":
+		cfg.Output = os.Stderr
+	default:
+		errs.Addf(raw.Path+".writer", "must be stdout or stderr, got %q", options.Writer)
+	}
+
+	if options.Colors != nil {
+		if options.Colors.Timestamp != "" {
+			cfg.Colors.TimestampColor = &options.Colors.Timestamp
+		}
+		if options.Colors.Message != "" {
+			cfg.Colors.MessageColor = &options.Colors.Message
+		}
+		if len(options.Colors.Levels) > 0 {
+			cfg.Colors.LevelColors = make(map[shared.Level]string, len(options.Colors.Levels))
+			for name, color := range options.Colors.Levels {
+				level, err := shared.ParseLevel(name)
+				errs.Add(raw.Path+".colors.levels."+name, err)
+				cfg.Colors.LevelColors[level] = color
+			}
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewConsoleAdapter(cfg), nil
+}
+
+// sanitizeOption returns the policy for a sanitize option, fallback if it is empty.
+func sanitizeOption(errs *shared.ConfigErrors, path, mode string, fallback *SanitizePolicy) *SanitizePolicy {
+	switch mode {
+	case "":
+		return fallback
+	case SanitizeEscape, SanitizeStrip, SanitizeReplace, SanitizeNone:
+		return &SanitizePolicy{Mode: mode}
+	default:
+		errs.Addf(path, "unknown mode %q", mode)
+		return fallback
+	}
+}
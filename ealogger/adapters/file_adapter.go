@@ -6,6 +6,14 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"time"
+)
+
+type FileEncoding = string
+
+const (
+	FileJSON    FileEncoding = "json"
+	FileConsole FileEncoding = "console"
 )
 
 type FileConfig struct {
@@ -13,6 +21,14 @@ type FileConfig struct {
 
 	Level    shared.Level
 	LJLogger *lumberjack.Logger
+
+	// Encoding is FileJSON, the default, or FileConsole.
+	Encoding FileEncoding
+
+	// Sanitize is applied to messages and string field values. If nil, it defaults to
+	// NewDefaultSanitizePolicy with FileConsole and is disabled with FileJSON, since the
+	// JSON encoding already escapes control characters.
+	Sanitize *SanitizePolicy
 }
 
 type FileAdapter struct {
@@ -26,32 +42,120 @@ func (a *FileAdapter) Log(log shared.Log) {
 	}
 
 	if log.Data.TraceName != "" {
-		log.Data.TraceName = fmt.Sprintf("%s: ", log.Data.TraceName)
+		log.Data.TraceName = fmt.Sprintf("%s: ", a.cfg.Sanitize.Sanitize(log.Data.TraceName))
+	}
+	log.Message = a.cfg.Sanitize.SanitizeMessage(&log)
+
+	fields := make([]zap.Field, 0, len(log.Data.Fields)+len(log.Data.TypedFields)+2)
+	for key, value := range log.Data.Fields {
+		if s, ok := value.(string); ok {
+			value = a.cfg.Sanitize.Sanitize(s)
+		}
+
+		fields = append(fields, zap.Any(a.cfg.Sanitize.Sanitize(key), value))
+	}
+	for _, field := range log.Data.TypedFields {
+		if a.cfg.Sanitize != nil {
+			field = sanitizeField(field, a.cfg.Sanitize)
+		}
+
+		fields = append(fields, zapField(field))
+	}
+
+	if log.Data.Error != nil {
+		fields = append(fields, zap.Any("error", shared.NewErrorInfo(log.Data.Error).Map()))
+	}
+
+	if len(log.Data.Stack) > 0 {
+		fields = append(fields, zap.String("stacktrace", log.Data.Stack.String()))
 	}
 
 	switch log.Level.String() {
+	case shared.TraceLevel.String():
+		a.writer.Debug(log.Data.TraceName+log.Message, fields...)
 	case shared.DebugLevel.String():
-		a.writer.Debug(log.Data.TraceName + log.Message)
+		a.writer.Debug(log.Data.TraceName+log.Message, fields...)
 	case shared.InfoLevel.String():
-		a.writer.Info(log.Data.TraceName + log.Message)
+		a.writer.Info(log.Data.TraceName+log.Message, fields...)
 	case shared.WarnLevel.String():
-		a.writer.Warn(log.Data.TraceName + log.Message)
+		a.writer.Warn(log.Data.TraceName+log.Message, fields...)
 	case shared.ErrorLevel.String():
-		a.writer.Error(log.Data.TraceName + log.Message)
+		a.writer.Error(log.Data.TraceName+log.Message, fields...)
 	case shared.FatalLevel.String():
-		a.writer.Fatal(log.Data.TraceName + log.Message)
+		a.writer.Fatal(log.Data.TraceName+log.Message, fields...)
 	case shared.UnselectedLevel.String():
-		a.writer.Info(log.Data.TraceName + log.Message)
+		a.writer.Info(log.Data.TraceName+log.Message, fields...)
 	default:
-		a.writer.Info(log.Data.TraceName + log.Message)
+		a.writer.Info(log.Data.TraceName+log.Message, fields...)
 	}
 }
 
+func (a *FileAdapter) IsEnabled(level shared.Level) bool {
+	return a.cfg.Enable && a.cfg.Level.IsEnabled(level)
+}
+
+func (a *FileAdapter) Close() error {
+	_ = a.writer.Sync()
+
+	return a.cfg.LJLogger.Close()
+}
+
 func (a *FileAdapter) Format(log *shared.Log) {
 
 }
 
+func zapField(field shared.Field) zap.Field {
+	switch field.Type {
+	case shared.StringType:
+		return zap.String(field.Key, field.Str)
+	case shared.IntType:
+		return zap.Int64(field.Key, field.Integer)
+	case shared.FloatType, shared.BoolType, shared.TimeType:
+		return zap.Any(field.Key, field.Value())
+	case shared.DurationType:
+		return zap.Duration(field.Key, time.Duration(field.Integer))
+	case shared.ErrorType, shared.StringerType:
+		return zap.String(field.Key, field.Text())
+	case shared.ObjectType:
+		if marshaler, ok := field.Interface.(shared.ObjectMarshaler); ok {
+			return zap.Object(field.Key, zapObject(marshaler.LogFields()))
+		}
+		return zap.Skip()
+	default:
+		return zap.Any(field.Key, field.Value())
+	}
+}
+
+func sanitizeField(field shared.Field, policy *SanitizePolicy) shared.Field {
+	field.Key = policy.Sanitize(field.Key)
+
+	switch field.Type {
+	case shared.StringType:
+		field.Str = policy.Sanitize(field.Str)
+	case shared.ErrorType, shared.StringerType:
+		return shared.String(field.Key, policy.Sanitize(field.Text()))
+	}
+
+	return field
+}
+
+type zapObject []shared.Field
+
+func (o zapObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, field := range o {
+		zapField(field).AddTo(enc)
+	}
+
+	return nil
+}
+
 func NewFileAdapter(cfg *FileConfig) *FileAdapter {
+	if cfg.Encoding == FileConsole && cfg.Sanitize == nil {
+		copied := *cfg
+		copied.Sanitize = NewDefaultSanitizePolicy()
+		cfg = &copied
+	}
+
 	return &FileAdapter{
 		cfg:    cfg,
 		writer: newFileLogger(cfg),
@@ -96,6 +200,9 @@ func newFileLogger(cfg *FileConfig) *zap.Logger {
 	pe := zap.NewProductionEncoderConfig()
 	pe.EncodeTime = zapcore.ISO8601TimeEncoder
 	fileEncoder := zapcore.NewJSONEncoder(pe)
+	if cfg.Encoding == FileConsole {
+		fileEncoder = zapcore.NewConsoleEncoder(pe)
+	}
 
 	ioWriter := cfg.LJLogger
 	core := zapcore.NewCore(fileEncoder, zapcore.AddSync(ioWriter), cfg.Level.ToZap())
@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileOptions are the options of "file" adapters in declarative configs.
+type FileOptions struct {
+	// Writer is the file name, "logs/logs.log" by default.
+	Writer string `json:"writer"`
+	// Formatter is "json", the default, or "console".
+	Formatter string `json:"formatter"`
+	// Sanitize is "escape", "strip", "replace" or "none", see SanitizePolicy. It defaults
+	// to "escape" with the console formatter and to "none" with json.
+	Sanitize string `json:"sanitize"`
+
+	Rotation *FileRotationOptions `json:"rotation"`
+}
+
+// FileRotationOptions holds the lumberjack settings of a file adapter.
+type FileRotationOptions struct {
+	MaxSize    int  `json:"max_size"`
+	MaxBackups int  `json:"max_backups"`
+	MaxAge     int  `json:"max_age"`
+	LocalTime  bool `json:"local_time"`
+	Compress   bool `json:"compress"`
+}
+
+func newFileAdapterFromConfig(raw *RawConfig) (Adapter, error) {
+	var options FileOptions
+	if err := raw.Decode(&options); err != nil {
+		return nil, err
+	}
+
+	var errs shared.ConfigErrors
+
+	cfg := defaultFileConfig()
+	cfg.Level = raw.Level
+	cfg.Sanitize = sanitizeOption(&errs, raw.Path+".sanitize", options.Sanitize, nil)
+
+	switch options.Formatter {
+	case "", FileJSON, FileConsole:
+		cfg.Encoding = options.Formatter
+	default:
+		errs.Addf(raw.Path+".formatter", "must be json or console, got %q", options.Formatter)
+	}
+
+	if options.Writer != "" {
+		cfg.LJLogger.Filename = options.Writer
+	}
+
+	if rotation := options.Rotation; rotation != nil {
+		if rotation.MaxSize < 0 {
+			errs.Addf(raw.Path+".rotation.max_size", "must not be negative")
+		}
+		if rotation.MaxBackups < 0 {
+			errs.Addf(raw.Path+".rotation.max_backups", "must not be negative")
+		}
+		if rotation.MaxAge < 0 {
+			errs.Addf(raw.Path+".rotation.max_age", "must not be negative")
+		}
+
+		cfg.LJLogger = &lumberjack.Logger{
+			Filename:   cfg.LJLogger.Filename,
+			MaxSize:    rotation.MaxSize,
+			MaxBackups: rotation.MaxBackups,
+			MaxAge:     rotation.MaxAge,
+			LocalTime:  rotation.LocalTime,
+			Compress:   rotation.Compress,
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewFileAdapter(cfg), nil
+}
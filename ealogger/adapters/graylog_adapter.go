@@ -1,61 +1,170 @@
 package adapters
 
 import (
+	"compress/flate"
+	"errors"
 	"fmt"
 	"github.com/Graylog2/go-gelf/gelf"
 	"github.com/eris-apple/ealogger/ealogger/shared"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+type GraylogTransport = string
+
+const (
+	GraylogUDP GraylogTransport = "udp"
+	GraylogTCP GraylogTransport = "tcp"
+	GraylogTLS GraylogTransport = "tls"
+
+	// GraylogHTTP posts messages to a GELF HTTP input; Addr is the full endpoint URL.
+	GraylogHTTP GraylogTransport = "http"
+)
+
+type GraylogCompression = string
+
+const (
+	GraylogGzip GraylogCompression = "gzip"
+	GraylogZlib GraylogCompression = "zlib"
+	GraylogNone GraylogCompression = "none"
+)
+
+type GraylogTLSConfig struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+type GraylogReconnectConfig struct {
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// BufferSize is the maximum number of messages kept in memory while the connection is down.
+	BufferSize int
+}
+
 type GraylogConfig struct {
 	Enable bool
 
 	Addr string
+	// Host identifies the source of the messages, the host name if empty.
 	Host string
 
 	Level shared.Level
+
+	// ShortMessageLength is the maximum length in runes of short_message; the untruncated text goes to full_message.
+	ShortMessageLength int
+
+	Transport GraylogTransport
+
+	// Compression and CompressionLevel are used only by the UDP transport.
+	Compression      GraylogCompression
+	CompressionLevel int
+
+	TLS       *GraylogTLSConfig
+	Reconnect *GraylogReconnectConfig
+	HTTP      *GraylogHTTPConfig
+}
+
+const defaultGraylogShortMessageLength = 250
+
+var (
+	graylogFieldRegexp      = regexp.MustCompile(`^_[\w.-]+$`)
+	graylogInvalidCharRegex = regexp.MustCompile(`[^\w.-]`)
+)
+
+type graylogWriter interface {
+	WriteMessage(m *gelf.Message) error
+	Close() error
 }
 
 type GraylogAdapter struct {
-	writer *gelf.Writer
+	writer graylogWriter
 	cfg    *GraylogConfig
 }
 
 func (a *GraylogAdapter) Log(log shared.Log) {
-	if !a.cfg.Enable || !a.cfg.Level.IsEnabled(log.Level) {
+	if !a.cfg.Enable || !a.cfg.Level.IsEnabled(log.Level) || a.writer == nil {
 		return
 	}
 
 	a.Format(&log)
 
+	short := truncateGraylogMessage(log.Message, a.cfg.ShortMessageLength)
+	full := graylogFullMessage(log.Message, log.Data.Error, log.Data.Stack)
+	if full == short {
+		full = ""
+	}
+
 	if err := a.writer.WriteMessage(&gelf.Message{
 		Level:    log.Level.ToGraylog(),
-		Full:     log.Data.TraceName + log.Message,
-		Short:    log.Data.TraceName + log.Message,
+		Full:     full,
+		Short:    short,
 		Host:     a.cfg.Host,
-		TimeUnix: float64(time.Now().Unix()),
+		TimeUnix: graylogTimestamp(log.Time),
 		Extra:    log.Data.Fields,
 	}); err != nil {
 		return
 	}
 }
 
+func (a *GraylogAdapter) IsEnabled(level shared.Level) bool {
+	return a.cfg.Enable && a.cfg.Level.IsEnabled(level)
+}
+
 func (a *GraylogAdapter) Format(log *shared.Log) {
-	if log.Data != nil {
-		log.Data.Fields["traceName"] = log.Data.TraceName
+	if log.Data == nil {
+		log.Data = &shared.LogData{}
+	}
 
-		if log.Data.Fields == nil {
-			log.Data.Fields = map[string]interface{}{}
-		}
+	extra := make(shared.LogField, len(log.Data.Fields)+len(log.Data.TypedFields)+4)
+	flattenGraylogFields(extra, "", log.Data.Fields)
+	for _, field := range log.Data.TypedFields {
+		addGraylogField(extra, field.Key, field.Value())
+	}
+
+	if log.Data.TraceName != "" {
+		extra["_trace_name"] = log.Data.TraceName
+		log.Message = fmt.Sprintf("[%s]: %s", log.Data.TraceName, log.Message)
+	}
+
+	if log.Data.Error != nil {
+		flattenGraylogFields(extra, "error", shared.NewErrorInfo(log.Data.Error).Map())
+	}
 
-		if log.Data.Error != nil {
-			log.Data.Fields["error"] = log.Data.Error
+	if log.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{log.PC}).Next()
+		if frame.File != "" {
+			extra["_file"] = frame.File
+			extra["_line"] = frame.Line
 		}
 	}
 
+	log.Data.Fields = extra
+}
+
+func (a *GraylogAdapter) Close() error {
+	if a.writer == nil {
+		return nil
+	}
+
+	return a.writer.Close()
 }
 
 func NewGraylogAdapter(cfg *GraylogConfig) *GraylogAdapter {
+	if cfg.Host == "" {
+		copied := *cfg
+		copied.Host = shared.Hostname()
+		cfg = &copied
+	}
+
 	return &GraylogAdapter{
 		cfg:    cfg,
 		writer: newGraylogLogger(cfg),
@@ -83,19 +192,161 @@ func NewDefaultGraylogAdapterWithLevel(level shared.Level) *GraylogAdapter {
 
 func defaultGraylogConfig() *GraylogConfig {
 	return &GraylogConfig{
-		Enable: true,
-		Level:  shared.DebugLevel,
-		Addr:   "localhost:12201",
-		Host:   "APP",
+		Enable:             true,
+		Level:              shared.DebugLevel,
+		Addr:               "localhost:12201",
+		Host:               shared.Hostname(),
+		ShortMessageLength: defaultGraylogShortMessageLength,
+		Transport:          GraylogUDP,
+		Compression:        GraylogGzip,
+		CompressionLevel:   flate.BestSpeed,
 	}
 }
 
-func newGraylogLogger(cfg *GraylogConfig) *gelf.Writer {
+func defaultGraylogReconnectConfig() *GraylogReconnectConfig {
+	return &GraylogReconnectConfig{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 30 * time.Second,
+		BufferSize: 1000,
+	}
+}
+
+func newGraylogLogger(cfg *GraylogConfig) graylogWriter {
+	switch cfg.Transport {
+	case GraylogTCP, GraylogTLS:
+		writer, err := newGraylogTCPWriter(cfg)
+		if err != nil {
+			fmt.Println("WARN: error with init graylog: ", err)
+			return nil
+		}
+
+		return writer
+	case GraylogHTTP:
+		return newGraylogHTTPWriter(cfg)
+	default:
+		return newGraylogUDPWriter(cfg)
+	}
+}
+
+func newGraylogUDPWriter(cfg *GraylogConfig) graylogWriter {
 	gelfWriter, err := gelf.NewWriter(cfg.Addr)
 	if err != nil {
 		fmt.Println("WARN: error with init graylog: ", err)
 		return nil
 	}
 
+	switch cfg.Compression {
+	case GraylogZlib:
+		gelfWriter.CompressionType = gelf.CompressZlib
+	case GraylogNone:
+		gelfWriter.CompressionType = gelf.CompressNone
+	default:
+		gelfWriter.CompressionType = gelf.CompressGzip
+	}
+
+	if cfg.CompressionLevel != 0 {
+		gelfWriter.CompressionLevel = cfg.CompressionLevel
+	}
+
 	return gelfWriter
 }
+
+func truncateGraylogMessage(message string, length int) string {
+	if length <= 0 {
+		length = defaultGraylogShortMessageLength
+	}
+
+	if utf8.RuneCountInString(message) <= length {
+		return message
+	}
+
+	return string([]rune(message)[:length])
+}
+
+// graylogFullMessage appends the unwrapped error chain and the stack trace to the message.
+// Without a captured stack, errors that print more detail with %+v contribute that detail instead.
+func graylogFullMessage(message string, err error, stack shared.Stack) string {
+	var b strings.Builder
+	b.WriteString(message)
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		b.WriteString("\n")
+		if e != err {
+			b.WriteString("caused by: ")
+		}
+		b.WriteString(e.Error())
+	}
+
+	if len(stack) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(stack.String())
+	} else if err != nil {
+		if detailed := fmt.Sprintf("%+v", err); detailed != err.Error() {
+			b.WriteString("\n\n")
+			b.WriteString(detailed)
+		}
+	}
+
+	return b.String()
+}
+
+func graylogTimestamp(t time.Time) float64 {
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// flattenGraylogFields writes fields into extra as GELF additional fields.
+// Nested maps and slices are flattened with dot separated keys.
+func flattenGraylogFields(extra shared.LogField, prefix string, fields map[string]interface{}) {
+	for key, value := range fields {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		addGraylogField(extra, key, value)
+	}
+}
+
+func addGraylogField(extra shared.LogField, key string, value interface{}) {
+	value = shared.ResolveValue(value)
+
+	switch nested := value.(type) {
+	case shared.LogField:
+		flattenGraylogFields(extra, key, nested)
+		return
+	case map[string]interface{}:
+		flattenGraylogFields(extra, key, nested)
+		return
+	case []interface{}:
+		items := make(map[string]interface{}, len(nested))
+		for i, item := range nested {
+			items[strconv.Itoa(i)] = item
+		}
+
+		flattenGraylogFields(extra, key, items)
+		return
+	case error:
+		value = nested.Error()
+	}
+
+	if key = sanitizeGraylogField(key); key != "" {
+		extra[key] = value
+	}
+}
+
+// sanitizeGraylogField converts key to a valid GELF additional field name.
+// It returns an empty string for the reserved "_id" field.
+func sanitizeGraylogField(key string) string {
+	if !graylogFieldRegexp.MatchString(key) {
+		key = "_" + graylogInvalidCharRegex.ReplaceAllString(strings.TrimPrefix(key, "_"), "_")
+	}
+
+	if key == "_id" || key == "_" {
+		return ""
+	}
+
+	return key
+}
@@ -0,0 +1,77 @@
+package adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Graylog2/go-gelf/gelf"
+)
+
+func TestGraylogUDPWriter_Compression(t *testing.T) {
+	for _, tt := range []struct {
+		compression GraylogCompression
+		decompress  func(io.Reader) (io.Reader, error)
+	}{
+		{GraylogGzip, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{GraylogZlib, func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+		{GraylogNone, func(r io.Reader) (io.Reader, error) { return r, nil }},
+	} {
+		t.Run(tt.compression, func(t *testing.T) {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			w := newGraylogUDPWriter(&GraylogConfig{Addr: conn.LocalAddr().String(), Compression: tt.compression})
+			if w == nil {
+				t.Fatal("no UDP writer")
+			}
+			defer w.Close()
+
+			if err := w.WriteMessage(&gelf.Message{Version: "1.1", Short: "compressed", Host: "test", Level: 6}); err != nil {
+				t.Fatal(err)
+			}
+
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			packet := make([]byte, 8192)
+			n, _, err := conn.ReadFrom(packet)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := tt.decompress(bytes.NewReader(packet[:n]))
+			if err != nil {
+				t.Fatalf("payload is not %s compressed: %v", tt.compression, err)
+			}
+
+			var msg map[string]any
+			if err := json.NewDecoder(r).Decode(&msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg["short_message"] != "compressed" || msg["host"] != "test" {
+				t.Fatalf("got %v", msg)
+			}
+		})
+	}
+}
+
+func TestNewGraylogAdapter_DoesNotMutateConfig(t *testing.T) {
+	cfg := &GraylogConfig{Enable: true, Addr: "127.0.0.1:12201", Transport: GraylogUDP}
+
+	a := NewGraylogAdapter(cfg)
+	defer a.Close()
+
+	if cfg.Host != "" {
+		t.Fatalf("the host default was written into the caller's config: %q", cfg.Host)
+	}
+	if a.cfg.Host == "" {
+		t.Fatal("expected the adapter to default its host")
+	}
+}
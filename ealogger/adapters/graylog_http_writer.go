@@ -0,0 +1,289 @@
+package adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/Graylog2/go-gelf/gelf"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type GraylogHTTPConfig struct {
+	Headers map[string]string
+
+	Username    string
+	Password    string
+	BearerToken string
+
+	Timeout time.Duration
+	Gzip    bool
+
+	// BatchSize greater than 1 enables batching. Batched messages are sent
+	// newline-delimited, as expected by the GELF HTTP bulk input.
+	BatchSize int
+	// FlushInterval is how often an incomplete batch is sent, one second by default.
+	FlushInterval time.Duration
+
+	// MaxRetries is 3 by default; a negative value disables retries.
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// QueueSize bounds the messages waiting to be sent; the oldest are dropped when it is full.
+	QueueSize int
+}
+
+// graylogHTTPWriter posts GELF JSON messages to a Graylog GELF HTTP input.
+// Messages are queued in a bounded buffer and posted by a background goroutine,
+// which also retries with backoff, so a slow or failing Graylog never blocks the
+// caller. When the queue is full the oldest messages are dropped.
+type graylogHTTPWriter struct {
+	mu      sync.Mutex
+	pending [][]byte
+
+	url    string
+	client *http.Client
+	cfg    *GraylogHTTPConfig
+
+	wake      chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func (w *graylogHTTPWriter) WriteMessage(m *gelf.Message) error {
+	if m.Version == "" {
+		m.Version = "1.1"
+	}
+
+	buf := new(bytes.Buffer)
+	if err := m.MarshalJSONBuf(buf); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.pending = append(w.pending, buf.Bytes())
+	if over := len(w.pending) - w.cfg.QueueSize; over > 0 {
+		w.pending = w.pending[over:]
+	}
+	full := len(w.pending) >= w.cfg.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Close stops the background goroutine after a last attempt to send the queued messages.
+func (w *graylogHTTPWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		<-w.stopped
+	})
+
+	return nil
+}
+
+func (w *graylogHTTPWriter) run() {
+	defer close(w.stopped)
+
+	var tick <-chan time.Time
+	if w.cfg.BatchSize > 1 {
+		ticker := time.NewTicker(w.cfg.FlushInterval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-w.wake:
+			w.flush(false)
+		case <-tick:
+			w.flush(true)
+		case <-w.done:
+			w.flush(true)
+			return
+		}
+	}
+}
+
+// flush posts the queued messages in batches. Unless partial is set, a
+// trailing batch smaller than BatchSize is left for the next flush.
+func (w *graylogHTTPWriter) flush(partial bool) {
+	for {
+		w.mu.Lock()
+		n := min(len(w.pending), w.cfg.BatchSize)
+		if n == 0 || (!partial && n < w.cfg.BatchSize) {
+			w.mu.Unlock()
+			return
+		}
+
+		batch := w.pending[:n:n]
+		w.pending = w.pending[n:]
+		w.mu.Unlock()
+
+		if err := w.send(bytes.Join(batch, []byte("\n"))); err != nil {
+			fmt.Println("WARN: error with send to graylog: ", err)
+		}
+	}
+}
+
+func (w *graylogHTTPWriter) send(body []byte) error {
+	if w.cfg.Gzip {
+		buf := new(bytes.Buffer)
+		zw := gzip.NewWriter(buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		body = buf.Bytes()
+	}
+
+	backoff := w.cfg.MinBackoff
+
+	var err error
+	for attempt := 0; attempt <= w.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-w.done:
+				// Closing: make one last attempt without waiting.
+				timer.Stop()
+				_, err = w.post(body)
+				return err
+			}
+
+			backoff *= 2
+			if backoff > w.cfg.MaxBackoff {
+				backoff = w.cfg.MaxBackoff
+			}
+		}
+
+		var retry bool
+		if retry, err = w.post(body); !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (w *graylogHTTPWriter) post(body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if w.cfg.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	for key, value := range w.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	switch {
+	case w.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+w.cfg.BearerToken)
+	case w.cfg.Username != "":
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return true, fmt.Errorf("graylog: unexpected status %s", resp.Status)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return false, fmt.Errorf("graylog: unexpected status %s", resp.Status)
+	}
+
+	return false, nil
+}
+
+func newGraylogHTTPWriter(cfg *GraylogConfig) *graylogHTTPWriter {
+	httpCfg := defaultGraylogHTTPConfig()
+	if cfg.HTTP != nil {
+		defaults := httpCfg
+
+		httpCfg = new(GraylogHTTPConfig)
+		*httpCfg = *cfg.HTTP
+
+		if httpCfg.Timeout <= 0 {
+			httpCfg.Timeout = defaults.Timeout
+		}
+		if httpCfg.FlushInterval <= 0 {
+			httpCfg.FlushInterval = defaults.FlushInterval
+		}
+		if httpCfg.MaxRetries == 0 {
+			httpCfg.MaxRetries = defaults.MaxRetries
+		}
+		if httpCfg.MinBackoff <= 0 {
+			httpCfg.MinBackoff = defaults.MinBackoff
+		}
+		if httpCfg.MaxBackoff <= 0 {
+			httpCfg.MaxBackoff = defaults.MaxBackoff
+		}
+		if httpCfg.QueueSize <= 0 {
+			httpCfg.QueueSize = defaults.QueueSize
+		}
+	}
+
+	if httpCfg.BatchSize < 1 {
+		httpCfg.BatchSize = 1
+	}
+	if httpCfg.MaxRetries < 0 {
+		httpCfg.MaxRetries = 0
+	}
+	if httpCfg.QueueSize < httpCfg.BatchSize {
+		httpCfg.QueueSize = httpCfg.BatchSize
+	}
+
+	w := &graylogHTTPWriter{
+		url:     cfg.Addr,
+		client:  &http.Client{},
+		cfg:     httpCfg,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+func defaultGraylogHTTPConfig() *GraylogHTTPConfig {
+	return &GraylogHTTPConfig{
+		Timeout:       5 * time.Second,
+		FlushInterval: time.Second,
+		MaxRetries:    3,
+		MinBackoff:    100 * time.Millisecond,
+		MaxBackoff:    5 * time.Second,
+		QueueSize:     1000,
+	}
+}
@@ -0,0 +1,271 @@
+package adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Graylog2/go-gelf/gelf"
+)
+
+type graylogHTTPRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// graylogHTTPServer records the requests it receives and answers with the
+// given statuses in turn, then with 202 Accepted.
+type graylogHTTPServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []graylogHTTPRequest
+	received chan struct{}
+}
+
+func newGraylogHTTPServer(t *testing.T, statuses ...int) *graylogHTTPServer {
+	t.Helper()
+
+	s := &graylogHTTPServer{statuses: statuses, received: make(chan struct{}, 100)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, graylogHTTPRequest{header: r.Header.Clone(), body: body})
+		status := http.StatusAccepted
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		s.mu.Unlock()
+
+		rw.WriteHeader(status)
+		s.received <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *graylogHTTPServer) wait(t *testing.T, n int) []graylogHTTPRequest {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d requests, want %d", i, n)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]graylogHTTPRequest(nil), s.requests...)
+}
+
+func newTestHTTPWriter(t *testing.T, url string, cfg *GraylogHTTPConfig) *graylogHTTPWriter {
+	t.Helper()
+
+	w := newGraylogHTTPWriter(&GraylogConfig{Addr: url, Transport: GraylogHTTP, HTTP: cfg})
+	t.Cleanup(func() { _ = w.Close() })
+
+	return w
+}
+
+func shortMessages(t *testing.T, body []byte) []string {
+	t.Helper()
+
+	var shorts []string
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		var msg map[string]any
+		if err := json.Unmarshal(line, &msg); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+
+		shorts = append(shorts, msg["short_message"].(string))
+	}
+
+	return shorts
+}
+
+func TestGraylogHTTPWriter_Batching(t *testing.T) {
+	s := newGraylogHTTPServer(t)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{BatchSize: 2})
+
+	for _, short := range []string{"a", "b", "c"} {
+		_ = w.WriteMessage(&gelf.Message{Short: short, Host: "test"})
+	}
+
+	requests := s.wait(t, 1)
+	if got := shortMessages(t, requests[0].body); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("first batch = %v, want [a b]", got)
+	}
+
+	// The incomplete batch is sent on Close.
+	_ = w.Close()
+
+	requests = s.wait(t, 1)
+	if got := shortMessages(t, requests[1].body); len(got) != 1 || got[0] != "c" {
+		t.Fatalf("last batch = %v, want [c]", got)
+	}
+}
+
+func TestGraylogHTTPWriter_FlushInterval(t *testing.T) {
+	s := newGraylogHTTPServer(t)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{BatchSize: 10, FlushInterval: 10 * time.Millisecond})
+
+	_ = w.WriteMessage(&gelf.Message{Short: "tick", Host: "test"})
+
+	requests := s.wait(t, 1)
+	if got := shortMessages(t, requests[0].body); len(got) != 1 || got[0] != "tick" {
+		t.Fatalf("got %v, want [tick]", got)
+	}
+}
+
+func TestGraylogHTTPWriter_GzipAndAuth(t *testing.T) {
+	s := newGraylogHTTPServer(t)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{
+		Gzip:        true,
+		BearerToken: "token",
+		Headers:     map[string]string{"X-Tenant": "team"},
+	})
+
+	_ = w.WriteMessage(&gelf.Message{Short: "zipped", Host: "test"})
+
+	req := s.wait(t, 1)[0]
+	if got := req.header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q", got)
+	}
+	if got := req.header.Get("Authorization"); got != "Bearer token" {
+		t.Fatalf("Authorization = %q", got)
+	}
+	if got := req.header.Get("X-Tenant"); got != "team" {
+		t.Fatalf("X-Tenant = %q", got)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(req.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := shortMessages(t, body); got[0] != "zipped" {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestGraylogHTTPWriter_BasicAuth(t *testing.T) {
+	s := newGraylogHTTPServer(t)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{Username: "user", Password: "pass"})
+
+	_ = w.WriteMessage(&gelf.Message{Short: "auth", Host: "test"})
+
+	req := &http.Request{Header: s.wait(t, 1)[0].header}
+	if user, pass, ok := req.BasicAuth(); !ok || user != "user" || pass != "pass" {
+		t.Fatalf("basic auth = %q, %q, %v", user, pass, ok)
+	}
+}
+
+func TestGraylogHTTPWriter_RetriesServerErrors(t *testing.T) {
+	s := newGraylogHTTPServer(t, http.StatusServiceUnavailable, http.StatusInternalServerError)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{MaxRetries: 3, MinBackoff: time.Millisecond})
+
+	_ = w.WriteMessage(&gelf.Message{Short: "retried", Host: "test"})
+
+	requests := s.wait(t, 3)
+	for _, req := range requests {
+		if got := shortMessages(t, req.body); got[0] != "retried" {
+			t.Fatalf("got %v", got)
+		}
+	}
+}
+
+func TestGraylogHTTPWriter_DoesNotRetryClientErrors(t *testing.T) {
+	s := newGraylogHTTPServer(t, http.StatusBadRequest)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{MaxRetries: 3, MinBackoff: time.Millisecond})
+
+	_ = w.WriteMessage(&gelf.Message{Short: "rejected", Host: "test"})
+	s.wait(t, 1)
+	_ = w.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(s.requests))
+	}
+}
+
+func TestGraylogHTTPWriter_DoesNotBlockOrMutateConfig(t *testing.T) {
+	block := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-block }))
+	defer s.Close()
+	defer close(block)
+
+	cfg := &GraylogHTTPConfig{QueueSize: 5, Timeout: 50 * time.Millisecond}
+	w := newTestHTTPWriter(t, s.URL, cfg)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		_ = w.WriteMessage(&gelf.Message{Short: "slow", Host: "test"})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("writes blocked for %s", elapsed)
+	}
+
+	w.mu.Lock()
+	pending := len(w.pending)
+	w.mu.Unlock()
+	if pending > 5 {
+		t.Fatalf("queue holds %d messages, want at most 5", pending)
+	}
+
+	if cfg.MinBackoff != 0 || cfg.MaxBackoff != 0 || cfg.BatchSize != 0 {
+		t.Fatalf("defaults were written into the caller's config: %+v", cfg)
+	}
+
+	_ = w.Close()
+	_ = w.Close()
+}
+
+func TestGraylogHTTPWriter_DefaultFlushInterval(t *testing.T) {
+	s := newGraylogHTTPServer(t)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{BatchSize: 10})
+
+	if w.cfg.FlushInterval != time.Second {
+		t.Fatalf("FlushInterval = %s, want 1s", w.cfg.FlushInterval)
+	}
+
+	// The incomplete batch is sent by the ticker, without Close.
+	_ = w.WriteMessage(&gelf.Message{Short: "pending", Host: "test"})
+
+	requests := s.wait(t, 1)
+	if got := shortMessages(t, requests[0].body); len(got) != 1 || got[0] != "pending" {
+		t.Fatalf("got %v, want [pending]", got)
+	}
+}
+
+func TestGraylogHTTPWriter_DefaultMaxRetries(t *testing.T) {
+	s := newGraylogHTTPServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	w := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{MinBackoff: time.Millisecond})
+
+	if w.cfg.MaxRetries != 3 {
+		t.Fatalf("MaxRetries = %d, want 3", w.cfg.MaxRetries)
+	}
+
+	_ = w.WriteMessage(&gelf.Message{Short: "retried", Host: "test"})
+	s.wait(t, 4)
+
+	disabled := newTestHTTPWriter(t, s.URL, &GraylogHTTPConfig{MaxRetries: -1})
+	if disabled.cfg.MaxRetries != 0 {
+		t.Fatalf("MaxRetries = %d, want 0", disabled.cfg.MaxRetries)
+	}
+}
@@ -0,0 +1,139 @@
+package adapters
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/shared"
+)
+
+// GraylogOptions are the options of "graylog" adapters in declarative configs.
+// Durations are written like "500ms" or "30s".
+type GraylogOptions struct {
+	Address            string `json:"address"`
+	Host               string `json:"host"`
+	Transport          string `json:"transport"`
+	Compression        string `json:"compression"`
+	CompressionLevel   int    `json:"compression_level"`
+	ShortMessageLength int    `json:"short_message_length"`
+
+	TLS       *GraylogTLSOptions       `json:"tls"`
+	Reconnect *GraylogReconnectOptions `json:"reconnect"`
+	HTTP      *GraylogHTTPOptions      `json:"http"`
+}
+
+type GraylogTLSOptions struct {
+	CAFile             string `json:"ca_file"`
+	CertFile           string `json:"cert_file"`
+	KeyFile            string `json:"key_file"`
+	ServerName         string `json:"server_name"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+type GraylogReconnectOptions struct {
+	MinBackoff string `json:"min_backoff"`
+	MaxBackoff string `json:"max_backoff"`
+	BufferSize int    `json:"buffer_size"`
+}
+
+type GraylogHTTPOptions struct {
+	Headers     map[string]string `json:"headers"`
+	Username    string            `json:"username"`
+	Password    string            `json:"password"`
+	BearerToken string            `json:"bearer_token"`
+
+	Timeout       string `json:"timeout"`
+	Gzip          bool   `json:"gzip"`
+	BatchSize     int    `json:"batch_size"`
+	FlushInterval string `json:"flush_interval"`
+	MaxRetries    int    `json:"max_retries"`
+	MinBackoff    string `json:"min_backoff"`
+	MaxBackoff    string `json:"max_backoff"`
+	QueueSize     int    `json:"queue_size"`
+}
+
+func newGraylogAdapterFromConfig(raw *RawConfig) (Adapter, error) {
+	var options GraylogOptions
+	if err := raw.Decode(&options); err != nil {
+		return nil, err
+	}
+
+	var errs shared.ConfigErrors
+
+	cfg := defaultGraylogConfig()
+	cfg.Level = raw.Level
+	cfg.Addr = options.Address
+	cfg.CompressionLevel = options.CompressionLevel
+
+	if options.Address == "" {
+		errs.Addf(raw.Path+".address", "required")
+	}
+
+	if options.Host != "" {
+		cfg.Host = options.Host
+	}
+
+	switch options.Transport {
+	case "":
+	case GraylogUDP, GraylogTCP, GraylogTLS, GraylogHTTP:
+		cfg.Transport = options.Transport
+	default:
+		errs.Addf(raw.Path+".transport", "unknown transport %q", options.Transport)
+	}
+
+	switch options.Compression {
+	case "":
+	case GraylogGzip, GraylogZlib, GraylogNone:
+		cfg.Compression = options.Compression
+	default:
+		errs.Addf(raw.Path+".compression", "unknown compression %q", options.Compression)
+	}
+
+	if options.ShortMessageLength < 0 {
+		errs.Addf(raw.Path+".short_message_length", "must not be negative")
+	} else if options.ShortMessageLength > 0 {
+		cfg.ShortMessageLength = options.ShortMessageLength
+	}
+
+	if tls := options.TLS; tls != nil {
+		if (tls.CertFile == "") != (tls.KeyFile == "") {
+			errs.Addf(raw.Path+".tls", "cert_file and key_file must be set together")
+		}
+
+		cfg.TLS = &GraylogTLSConfig{
+			CAFile:             tls.CAFile,
+			CertFile:           tls.CertFile,
+			KeyFile:            tls.KeyFile,
+			ServerName:         tls.ServerName,
+			InsecureSkipVerify: tls.InsecureSkipVerify,
+		}
+	}
+
+	if reconnect := options.Reconnect; reconnect != nil {
+		cfg.Reconnect = &GraylogReconnectConfig{
+			MinBackoff: errs.Duration(raw.Path+".reconnect.min_backoff", reconnect.MinBackoff),
+			MaxBackoff: errs.Duration(raw.Path+".reconnect.max_backoff", reconnect.MaxBackoff),
+			BufferSize: reconnect.BufferSize,
+		}
+	}
+
+	if http := options.HTTP; http != nil {
+		cfg.HTTP = &GraylogHTTPConfig{
+			Headers:       http.Headers,
+			Username:      http.Username,
+			Password:      http.Password,
+			BearerToken:   http.BearerToken,
+			Timeout:       errs.Duration(raw.Path+".http.timeout", http.Timeout),
+			Gzip:          http.Gzip,
+			BatchSize:     http.BatchSize,
+			FlushInterval: errs.Duration(raw.Path+".http.flush_interval", http.FlushInterval),
+			MaxRetries:    http.MaxRetries,
+			MinBackoff:    errs.Duration(raw.Path+".http.min_backoff", http.MinBackoff),
+			MaxBackoff:    errs.Duration(raw.Path+".http.max_backoff", http.MaxBackoff),
+			QueueSize:     http.QueueSize,
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewGraylogAdapter(cfg), nil
+}
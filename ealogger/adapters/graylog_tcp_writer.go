@@ -0,0 +1,262 @@
+package adapters
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/Graylog2/go-gelf/gelf"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	graylogDialTimeout  = 5 * time.Second
+	graylogWriteTimeout = 5 * time.Second
+)
+
+// graylogTCPWriter sends null-byte delimited GELF messages over TCP or TLS.
+// Messages are queued in a bounded buffer and written by a background goroutine,
+// which also reconnects with backoff, so a slow or unreachable Graylog never
+// blocks the caller. When the buffer is full the oldest messages are dropped.
+type graylogTCPWriter struct {
+	mu      sync.Mutex
+	pending [][]byte
+
+	addr      string
+	tlsConfig *tls.Config
+	reconnect *GraylogReconnectConfig
+
+	// conn is used only by the background goroutine.
+	conn net.Conn
+
+	wake      chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func (w *graylogTCPWriter) WriteMessage(m *gelf.Message) error {
+	if m.Version == "" {
+		m.Version = "1.1"
+	}
+
+	buf := new(bytes.Buffer)
+	if err := m.MarshalJSONBuf(buf); err != nil {
+		return err
+	}
+	buf.WriteByte(0)
+
+	w.mu.Lock()
+	w.pending = append(w.pending, buf.Bytes())
+	w.trim()
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Close stops the background goroutine after a last attempt to send the queued messages.
+func (w *graylogTCPWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		<-w.stopped
+	})
+
+	return nil
+}
+
+func (w *graylogTCPWriter) run() {
+	defer close(w.stopped)
+	defer w.disconnect()
+
+	var backoff time.Duration
+
+	for {
+		select {
+		case <-w.wake:
+		case <-w.done:
+			_ = w.flush()
+			return
+		}
+
+		for w.flush() != nil {
+			backoff = w.nextBackoff(backoff)
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-w.done:
+				timer.Stop()
+				_ = w.flush()
+				return
+			}
+		}
+
+		backoff = 0
+	}
+}
+
+// flush writes the queued messages, putting back the ones that could not be written.
+func (w *graylogTCPWriter) flush() error {
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if w.conn == nil {
+		conn, err := w.dial()
+		if err != nil {
+			w.requeue(batch)
+			return err
+		}
+
+		w.conn = conn
+	}
+
+	for i, msg := range batch {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(graylogWriteTimeout))
+
+		if _, err := w.conn.Write(msg); err != nil {
+			w.disconnect()
+			w.requeue(batch[i:])
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *graylogTCPWriter) requeue(batch [][]byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(batch, w.pending...)
+	w.trim()
+}
+
+// trim drops the oldest messages above the buffer size. It must be called with mu held.
+func (w *graylogTCPWriter) trim() {
+	if over := len(w.pending) - w.reconnect.BufferSize; over > 0 {
+		w.pending = w.pending[over:]
+	}
+}
+
+func (w *graylogTCPWriter) disconnect() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *graylogTCPWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: graylogDialTimeout}
+
+	if w.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", w.addr, w.tlsConfig)
+	}
+
+	return dialer.Dial("tcp", w.addr)
+}
+
+func (w *graylogTCPWriter) nextBackoff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		backoff = w.reconnect.MinBackoff
+	} else {
+		backoff *= 2
+	}
+
+	if backoff > w.reconnect.MaxBackoff {
+		backoff = w.reconnect.MaxBackoff
+	}
+
+	return backoff
+}
+
+func newGraylogTCPWriter(cfg *GraylogConfig) (*graylogTCPWriter, error) {
+	reconnect := defaultGraylogReconnectConfig()
+	if cfg.Reconnect != nil {
+		if cfg.Reconnect.MinBackoff > 0 {
+			reconnect.MinBackoff = cfg.Reconnect.MinBackoff
+		}
+		if cfg.Reconnect.MaxBackoff > 0 {
+			reconnect.MaxBackoff = cfg.Reconnect.MaxBackoff
+		}
+		if cfg.Reconnect.BufferSize > 0 {
+			reconnect.BufferSize = cfg.Reconnect.BufferSize
+		}
+	}
+
+	w := &graylogTCPWriter{
+		addr:      cfg.Addr,
+		reconnect: reconnect,
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	if cfg.Transport == GraylogTLS {
+		tlsConfig, err := newGraylogTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		w.tlsConfig = tlsConfig
+	}
+
+	if conn, err := w.dial(); err != nil {
+		fmt.Println("WARN: error with connect to graylog, will retry: ", err)
+	} else {
+		w.conn = conn
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+func newGraylogTLSConfig(cfg *GraylogTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg == nil {
+		return tlsConfig, nil
+	}
+
+	tlsConfig.ServerName = cfg.ServerName
+	tlsConfig.InsecureSkipVerify = cfg.InsecureSkipVerify
+
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("graylog: read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("graylog: no certificates found in %s", cfg.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("graylog: load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
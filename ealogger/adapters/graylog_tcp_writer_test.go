@@ -0,0 +1,279 @@
+package adapters
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Graylog2/go-gelf/gelf"
+)
+
+func newTestTCPWriter(t *testing.T, addr string) *graylogTCPWriter {
+	t.Helper()
+
+	w, err := newGraylogTCPWriter(&GraylogConfig{
+		Addr:      addr,
+		Transport: GraylogTCP,
+		Reconnect: &GraylogReconnectConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, BufferSize: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+
+	return w
+}
+
+// readGELF reads one null-byte delimited message from r.
+func readGELF(t *testing.T, r *bufio.Reader) map[string]any {
+	t.Helper()
+
+	frame, err := r.ReadBytes(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal(frame[:len(frame)-1], &msg); err != nil {
+		t.Fatalf("invalid frame %q: %v", frame, err)
+	}
+
+	return msg
+}
+
+func acceptConn(t *testing.T, ln net.Listener) net.Conn {
+	t.Helper()
+
+	if tcp, ok := ln.(*net.TCPListener); ok {
+		_ = tcp.SetDeadline(time.Now().Add(5 * time.Second))
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestGraylogTCPWriter_Framing(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	w := newTestTCPWriter(t, ln.Addr().String())
+	conn := acceptConn(t, ln)
+
+	for _, short := range []string{"first", "second"} {
+		if err := w.WriteMessage(&gelf.Message{Short: short, Host: "test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"first", "second"} {
+		msg := readGELF(t, r)
+		if msg["short_message"] != want || msg["version"] != "1.1" {
+			t.Fatalf("got %v, want short_message %q", msg, want)
+		}
+	}
+}
+
+func TestGraylogTCPWriter_ReconnectsAndResendsBuffered(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	w := newTestTCPWriter(t, addr)
+
+	if err := w.WriteMessage(&gelf.Message{Short: "buffered", Host: "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("address %s was taken in the meantime: %v", addr, err)
+	}
+	defer ln.Close()
+
+	msg := readGELF(t, bufio.NewReader(acceptConn(t, ln)))
+	if msg["short_message"] != "buffered" {
+		t.Fatalf("got %v, want the buffered message", msg)
+	}
+}
+
+func TestGraylogTCPWriter_ReconnectsAfterServerClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	w := newTestTCPWriter(t, ln.Addr().String())
+	_ = acceptConn(t, ln).Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				_ = w.WriteMessage(&gelf.Message{Short: "after", Host: "test"})
+			}
+		}
+	}()
+
+	msg := readGELF(t, bufio.NewReader(acceptConn(t, ln)))
+	if msg["short_message"] != "after" {
+		t.Fatalf("got %v", msg)
+	}
+}
+
+func TestGraylogTCPWriter_DoesNotBlockWhileDown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	w := newTestTCPWriter(t, addr)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		_ = w.WriteMessage(&gelf.Message{Short: "dropped", Host: "test"})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("writes blocked for %s", elapsed)
+	}
+
+	w.mu.Lock()
+	pending := len(w.pending)
+	w.mu.Unlock()
+	if pending > 10 {
+		t.Fatalf("buffer holds %d messages, want at most 10", pending)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestCertificate returns a self-signed certificate for localhost and 127.0.0.1,
+// and the path of a CA file holding it.
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func TestGraylogTCPWriter_TLS(t *testing.T) {
+	cert, caFile := newTestCertificate(t)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// The writer dials and completes the handshake while it is created.
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		_ = conn.(*tls.Conn).Handshake()
+		accepted <- conn
+	}()
+
+	w, err := newGraylogTCPWriter(&GraylogConfig{
+		Addr:      ln.Addr().String(),
+		Transport: GraylogTLS,
+		TLS:       &GraylogTLSConfig{CAFile: caFile, ServerName: "localhost"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+		defer conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("no TLS connection")
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if err := w.WriteMessage(&gelf.Message{Short: "secure", Host: "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := readGELF(t, bufio.NewReader(conn)); msg["short_message"] != "secure" {
+		t.Fatalf("got %v", msg)
+	}
+}
+
+func TestNewGraylogTLSConfig_InvalidCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newGraylogTLSConfig(&GraylogTLSConfig{CAFile: caFile}); err == nil {
+		t.Fatal("expected an error for a CA file without certificates")
+	}
+}
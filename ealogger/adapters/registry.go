@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Factory builds an adapter of a registered type from its config.
+type Factory func(cfg *RawConfig) (Adapter, error)
+
+// RawConfig is the config of a single adapter. Options holds the raw subtree with the
+// settings specific to the adapter type, to be decoded by the factory.
+type RawConfig struct {
+	Name  string
+	Type  string
+	Level shared.Level
+
+	Options map[string]any
+
+	// Path locates the options in the whole config, such as "adapters[1].options",
+	// and prefixes the errors returned by Decode.
+	Path string
+	// DecodeHook, if set, is applied to every value decoded by Decode,
+	// e.g. to override it from the environment.
+	DecodeHook func(v any) error
+}
+
+// Decode decodes the options into v, which is typically a pointer to a struct with json tags.
+// Unknown keys are reported as errors.
+func (c *RawConfig) Decode(v any) error {
+	data, err := json.Marshal(c.Options)
+	if err != nil {
+		return &shared.ConfigError{Path: c.Path, Err: err}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &shared.ConfigError{Path: c.Path, Err: err}
+	}
+
+	if c.DecodeHook != nil {
+		return c.DecodeHook(v)
+	}
+
+	return nil
+}
+
+// TypeInfo describes a registered adapter type, e.g. for tooling listing what a binary supports.
+type TypeInfo struct {
+	Type        string
+	Description string
+
+	// Options lists the keys accepted in the options of the type, if known.
+	Options []OptionInfo
+}
+
+type OptionInfo struct {
+	// Key is the dotted path of the option, such as "tls.ca_file".
+	Key  string
+	Type string
+}
+
+type registration struct {
+	info    TypeInfo
+	factory Factory
+}
+
+var registry = struct {
+	sync.RWMutex
+	types map[string]registration
+}{types: make(map[string]registration)}
+
+// Register makes an adapter type available to declarative configs. Registering a type
+// again replaces it.
+func Register(typ string, factory Factory) {
+	RegisterType(TypeInfo{Type: typ}, factory)
+}
+
+// RegisterType registers an adapter type together with its description.
+func RegisterType(info TypeInfo, factory Factory) {
+	if info.Type == "" || factory == nil {
+		panic("adapters: Register requires a type and a factory")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.types[info.Type] = registration{info: info, factory: factory}
+}
+
+// Types returns the registered adapter types sorted by name.
+func Types() []TypeInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	types := make([]TypeInfo, 0, len(registry.types))
+	for _, r := range registry.types {
+		types = append(types, r.info)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+
+	return types
+}
+
+func LookupType(typ string) (TypeInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	r, ok := registry.types[typ]
+
+	return r.info, ok
+}
+
+// New builds an adapter with the factory registered for cfg.Type.
+func New(cfg *RawConfig) (Adapter, error) {
+	registry.RLock()
+	r, ok := registry.types[cfg.Type]
+	registry.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown adapter type %q", cfg.Type)
+	}
+
+	return r.factory(cfg)
+}
+
+// DescribeOptions lists the keys of an options struct from its json tags, for TypeInfo.Options.
+func DescribeOptions(options any) []OptionInfo {
+	var infos []OptionInfo
+	describeOptions(reflect.TypeOf(options), "", &infos)
+
+	return infos
+}
+
+func describeOptions(t reflect.Type, prefix string, infos *[]OptionInfo) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			describeOptions(fieldType, prefix+key+".", infos)
+			continue
+		}
+
+		*infos = append(*infos, OptionInfo{Key: prefix + key, Type: fieldType.String()})
+	}
+}
+
+func init() {
+	RegisterType(TypeInfo{
+		Type:        "console",
+		Description: "Colored text logs on stdout or stderr",
+		Options:     DescribeOptions(ConsoleOptions{}),
+	}, newConsoleAdapterFromConfig)
+
+	RegisterType(TypeInfo{
+		Type:        "file",
+		Description: "JSON or text logs in a rotated file",
+		Options:     DescribeOptions(FileOptions{}),
+	}, newFileAdapterFromConfig)
+
+	RegisterType(TypeInfo{
+		Type:        "graylog",
+		Description: "GELF messages sent to Graylog over UDP, TCP, TLS or HTTP",
+		Options:     DescribeOptions(GraylogOptions{}),
+	}, newGraylogAdapterFromConfig)
+}
@@ -0,0 +1,145 @@
+package adapters
+
+import (
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type SanitizeMode = string
+
+const (
+	// SanitizeEscape writes control characters as Go escape sequences, such as \n or \x1b.
+	SanitizeEscape SanitizeMode = "escape"
+	// SanitizeStrip removes control characters and ANSI escape sequences.
+	SanitizeStrip SanitizeMode = "strip"
+	// SanitizeReplace replaces control characters and ANSI escape sequences with SanitizePolicy.Replacement.
+	SanitizeReplace SanitizeMode = "replace"
+	SanitizeNone    SanitizeMode = "none"
+)
+
+// SanitizePolicy protects plain-text sinks against log injection by neutralizing
+// newlines, control characters and ANSI escape sequences in messages and field values.
+// It is applied before an adapter adds its own styling.
+type SanitizePolicy struct {
+	Mode SanitizeMode
+
+	// Replacement is used by SanitizeReplace, U+FFFD if empty.
+	Replacement string
+	// AllowNewlines keeps \n intact in multi-line messages, such as DebugJSON output,
+	// see SanitizeMultiline. Other messages, field values and names always have their
+	// newlines neutralized.
+	AllowNewlines bool
+}
+
+var ansiSequence = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+func (p *SanitizePolicy) Sanitize(s string) string {
+	return p.sanitize(s, false)
+}
+
+// SanitizeMultiline sanitizes a message that spans several lines on purpose
+// (shared.LogData.Multiline). Its newlines are kept only if AllowNewlines is set.
+func (p *SanitizePolicy) SanitizeMultiline(s string) string {
+	return p.sanitize(s, p != nil && p.AllowNewlines)
+}
+
+// SanitizeMessage sanitizes the message of log, with SanitizeMultiline if it is multi-line.
+func (p *SanitizePolicy) SanitizeMessage(log *shared.Log) string {
+	if log.Data.Multiline {
+		return p.SanitizeMultiline(log.Message)
+	}
+
+	return p.Sanitize(log.Message)
+}
+
+func (p *SanitizePolicy) sanitize(s string, keepNewlines bool) string {
+	if p == nil || p.Mode == SanitizeNone || p.Mode == "" || !needsSanitizing(s, keepNewlines) {
+		return s
+	}
+
+	switch p.Mode {
+	case SanitizeStrip:
+		s = ansiSequence.ReplaceAllString(s, "")
+	case SanitizeReplace:
+		s = ansiSequence.ReplaceAllString(s, p.replacement())
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		if !isUnsafe(r, keepNewlines) {
+			b.WriteRune(r)
+			continue
+		}
+
+		switch p.Mode {
+		case SanitizeEscape:
+			b.WriteString(escapeRune(r))
+		case SanitizeReplace:
+			b.WriteString(p.replacement())
+		}
+	}
+
+	return b.String()
+}
+
+func needsSanitizing(s string, keepNewlines bool) bool {
+	for _, r := range s {
+		if isUnsafe(r, keepNewlines) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnsafe reports whether r can break a log line or control the terminal.
+// Unicode line separators and bidirectional overrides are unsafe as well.
+// Tabs are kept, as are newlines if keepNewlines is set.
+func isUnsafe(r rune, keepNewlines bool) bool {
+	switch r {
+	case '\t':
+		return false
+	case '\n':
+		return !keepNewlines
+	case '\u2028', '\u2029', '\u202a', '\u202b', '\u202c', '\u202d', '\u202e', '\u2066', '\u2067', '\u2068', '\u2069':
+		return true
+	}
+
+	return unicode.IsControl(r)
+}
+
+func (p *SanitizePolicy) replacement() string {
+	if p.Replacement == "" {
+		return "\ufffd"
+	}
+
+	return p.Replacement
+}
+
+func escapeRune(r rune) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case 0x1b:
+		return `\x1b`
+	}
+
+	if r < 0x80 {
+		return fmt.Sprintf(`\x%02x`, r)
+	}
+
+	return fmt.Sprintf(`\u%04x`, r)
+}
+
+func NewDefaultSanitizePolicy() *SanitizePolicy {
+	return &SanitizePolicy{
+		Mode: SanitizeEscape,
+	}
+}
@@ -0,0 +1,127 @@
+package adapters
+
+import (
+	"context"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+type SlogConfig struct {
+	Enable bool
+
+	Level shared.Level
+}
+
+// SlogAdapter forwards logs into an existing slog.Handler.
+type SlogAdapter struct {
+	writer slog.Handler
+	cfg    *SlogConfig
+}
+
+func (a *SlogAdapter) Log(log shared.Log) {
+	if !a.cfg.Enable || !a.cfg.Level.IsEnabled(log.Level) {
+		return
+	}
+
+	ctx := context.Background()
+	level := log.Level.ToSlog()
+	if !a.writer.Enabled(ctx, level) {
+		return
+	}
+
+	a.Format(&log)
+
+	if log.Time.IsZero() {
+		log.Time = time.Now()
+	}
+
+	record := slog.NewRecord(log.Time, level, log.Message, log.PC)
+
+	if log.Data.TraceName != "" {
+		record.AddAttrs(slog.String("trace_name", log.Data.TraceName))
+	}
+
+	if log.Data.Error != nil {
+		record.AddAttrs(slog.Any("error", log.Data.Error))
+	}
+
+	keys := make([]string, 0, len(log.Data.Fields))
+	for key := range log.Data.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		record.AddAttrs(slog.Any(key, log.Data.Fields[key]))
+	}
+
+	for _, field := range log.Data.TypedFields {
+		record.AddAttrs(slogAttr(field))
+	}
+
+	if len(log.Data.Stack) > 0 {
+		record.AddAttrs(slog.String("stacktrace", log.Data.Stack.String()))
+	}
+
+	_ = a.writer.Handle(ctx, record)
+}
+
+func (a *SlogAdapter) IsEnabled(level shared.Level) bool {
+	return a.cfg.Enable && a.cfg.Level.IsEnabled(level)
+}
+
+func (a *SlogAdapter) Format(log *shared.Log) {
+
+}
+
+func slogAttr(field shared.Field) slog.Attr {
+	switch field.Type {
+	case shared.StringType:
+		return slog.String(field.Key, field.Str)
+	case shared.IntType:
+		return slog.Int64(field.Key, field.Integer)
+	case shared.DurationType:
+		return slog.Duration(field.Key, time.Duration(field.Integer))
+	case shared.ObjectType:
+		marshaler, ok := field.Interface.(shared.ObjectMarshaler)
+		if !ok {
+			return slog.Any(field.Key, nil)
+		}
+
+		nested := marshaler.LogFields()
+		attrs := make([]any, len(nested))
+		for i, f := range nested {
+			attrs[i] = slogAttr(f)
+		}
+
+		return slog.Group(field.Key, attrs...)
+	default:
+		return slog.Any(field.Key, field.Value())
+	}
+}
+
+func NewSlogAdapter(handler slog.Handler) *SlogAdapter {
+	return &SlogAdapter{
+		cfg:    defaultSlogConfig(),
+		writer: handler,
+	}
+}
+
+func NewSlogAdapterWithLevel(handler slog.Handler, level shared.Level) *SlogAdapter {
+	cfg := defaultSlogConfig()
+	cfg.Level = level
+
+	return &SlogAdapter{
+		cfg:    cfg,
+		writer: handler,
+	}
+}
+
+func defaultSlogConfig() *SlogConfig {
+	return &SlogConfig{
+		Enable: true,
+		Level:  shared.DebugLevel,
+	}
+}
@@ -0,0 +1,154 @@
+package ealogger
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/adapters"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"os"
+	"runtime/debug"
+)
+
+// Option configures a Logger created with NewLoggerWithOptions.
+type Option func(l *Logger)
+
+// WithBaseFields adds fields to every log of the logger.
+func WithBaseFields(fields ...Field) Option {
+	return func(l *Logger) {
+		l.SetBaseFields(appendBaseFields(l.BaseFields(), fields, true)...)
+	}
+}
+
+func WithService(name string) Option {
+	return WithBaseFields(String("service", name))
+}
+
+func WithEnv(env string) Option {
+	return WithBaseFields(String("env", env))
+}
+
+func WithVersion(version string) Option {
+	return WithBaseFields(String("version", version))
+}
+
+// WithHostname adds the host name as the "hostname" field.
+func WithHostname() Option {
+	return WithBaseFields(String("hostname", shared.Hostname()))
+}
+
+// WithPID adds the process ID as the "pid" field.
+func WithPID() Option {
+	return WithBaseFields(Int("pid", os.Getpid()))
+}
+
+// WithBuildInfo adds the "version", "revision" and "module" fields from the build
+// information embedded in the binary. Fields set by other options, such as WithVersion,
+// are not overridden.
+func WithBuildInfo() Option {
+	return func(l *Logger) {
+		l.SetBaseFields(appendBaseFields(l.BaseFields(), buildInfoFields(), false)...)
+	}
+}
+
+func buildInfoFields() []Field {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	var fields []Field
+	if version := info.Main.Version; version != "" && version != "(devel)" {
+		fields = append(fields, String("version", version))
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			fields = append(fields, String("revision", setting.Value))
+		}
+	}
+
+	if info.Main.Path != "" {
+		fields = append(fields, String("module", info.Main.Path))
+	}
+
+	return fields
+}
+
+// appendBaseFields returns base with fields added. Fields with a key already in base
+// replace it if override is set and are skipped otherwise.
+func appendBaseFields(base, fields []Field, override bool) []Field {
+	merged := append([]Field{}, base...)
+
+	for _, field := range fields {
+		i := 0
+		for i < len(merged) && merged[i].Key != field.Key {
+			i++
+		}
+
+		switch {
+		case i == len(merged):
+			merged = append(merged, field)
+		case override:
+			merged[i] = field
+		}
+	}
+
+	return merged
+}
+
+// mergeBaseFields returns the typed fields of data preceded by the base fields
+// whose keys are not set by the log itself.
+func mergeBaseFields(base []Field, data *shared.LogData) []Field {
+	overridden := func(key string) bool {
+		if _, ok := data.Fields[key]; ok {
+			return true
+		}
+		for _, field := range data.TypedFields {
+			if field.Key == key {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for i, field := range base {
+		if !overridden(field.Key) {
+			continue
+		}
+
+		merged := make([]Field, i, len(base)+len(data.TypedFields))
+		copy(merged, base[:i])
+		for _, field := range base[i+1:] {
+			if !overridden(field.Key) {
+				merged = append(merged, field)
+			}
+		}
+
+		return append(merged, data.TypedFields...)
+	}
+
+	return appendFields(base, data.TypedFields)
+}
+
+// SetBaseFields replaces the fields added to every log of the logger.
+func (l *Logger) SetBaseFields(fields ...Field) {
+	if len(fields) == 0 {
+		fields = nil
+	}
+
+	l.updateSettings(func(s *settings) {
+		s.base = fields
+	})
+}
+
+func (l *Logger) BaseFields() []Field {
+	return l.snapshot().base
+}
+
+func NewLoggerWithOptions(list []adapters.Adapter, options ...Option) *Logger {
+	l := NewLogger(list...)
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
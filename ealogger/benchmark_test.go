@@ -0,0 +1,77 @@
+package ealogger
+
+import (
+	"errors"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"testing"
+)
+
+type discardAdapter struct {
+	level shared.Level
+}
+
+func (a *discardAdapter) Log(log shared.Log) {}
+
+func (a *discardAdapter) Format(log *shared.Log) {}
+
+func (a *discardAdapter) IsEnabled(level shared.Level) bool {
+	return a.level.IsEnabled(level)
+}
+
+func newBenchmarkLogger() *Logger {
+	return NewLogger(
+		&discardAdapter{level: shared.InfoLevel},
+		&discardAdapter{level: shared.WarnLevel},
+	)
+}
+
+func BenchmarkLogger_DisabledLevel(b *testing.B) {
+	l := newBenchmarkLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debug("disabled message")
+		l.Debugf("disabled %s", "message")
+		l.Debugw("disabled message", String("key", "value"), Int("n", 1))
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { l.Debugw("disabled", String("key", "value")) }); allocs != 0 {
+		b.Fatalf("expected no allocations for a disabled level, got %v", allocs)
+	}
+}
+
+func BenchmarkEntry_DisabledLevel(b *testing.B) {
+	entry := newBenchmarkLogger().WithName("bench").With(String("service", "api"))
+	err := errors.New("ignored")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entry.WithError(err).Debug("disabled message")
+	}
+}
+
+func BenchmarkLogger_EnabledLevel(b *testing.B) {
+	l := newBenchmarkLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("enabled message")
+	}
+}
+
+func BenchmarkLogger_EnabledLevelWithFields(b *testing.B) {
+	l := newBenchmarkLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Warnw("enabled message", String("key", "value"), Int("n", 1))
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { l.Warnw("enabled", String("key", "value")) }); allocs > 1 {
+		b.Fatalf("expected at most one allocation for an enabled level, got %v", allocs)
+	}
+}
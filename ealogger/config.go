@@ -0,0 +1,528 @@
+package ealogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/adapters"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ConfigFormat = string
+
+const (
+	ConfigJSON ConfigFormat = "json"
+	ConfigYAML ConfigFormat = "yaml"
+)
+
+// Config describes a Logger declaratively. It can be loaded from YAML or JSON with
+// LoadConfig and ParseConfig, and from EALOGGER_* environment variables with LoadEnv.
+type Config struct {
+	// Level is a level spec such as "info,db=debug", see ParseLevelSpec.
+	Level string `json:"level" yaml:"level"`
+	// Stacktrace is the level from which stacks are captured, see Logger.SetStacktraceLevel.
+	Stacktrace string `json:"stacktrace" yaml:"stacktrace"`
+
+	// Fields are added to every log, such as service and env, see SetBaseFields.
+	Fields map[string]string `json:"fields" yaml:"fields"`
+	// Process adds the hostname and pid fields.
+	Process bool `json:"process" yaml:"process"`
+	// BuildInfo adds the version, revision and module fields, see WithBuildInfo.
+	BuildInfo bool `json:"build_info" yaml:"build_info"`
+
+	Adapters  []AdapterConfig  `json:"adapters" yaml:"adapters"`
+	Redaction *RedactionConfig `json:"redaction" yaml:"redaction"`
+	Sampling  *SamplingConfig  `json:"sampling" yaml:"sampling"`
+}
+
+type AdapterConfig struct {
+	// Name identifies the adapter and defaults to its type.
+	Name string `json:"name" yaml:"name"`
+	// Type is a type registered with adapters.Register, such as "console", "file" or "graylog".
+	Type  string `json:"type" yaml:"type"`
+	Level string `json:"level" yaml:"level"`
+
+	// Names and ExcludeNames route logs to the adapter by name, see RouteAdapter.
+	Names        []string `json:"names" yaml:"names"`
+	ExcludeNames []string `json:"exclude_names" yaml:"exclude_names"`
+
+	// Options holds the settings specific to the type, passed as is to its factory,
+	// e.g. adapters.ConsoleOptions for "console".
+	Options map[string]any `json:"options" yaml:"options"`
+}
+
+type RedactionConfig struct {
+	// Defaults adds the rules of NewDefaultRedactor before Rules.
+	Defaults bool                  `json:"defaults" yaml:"defaults"`
+	HashKey  string                `json:"hash_key" yaml:"hash_key"`
+	Rules    []RedactionRuleConfig `json:"rules" yaml:"rules"`
+}
+
+type RedactionRuleConfig struct {
+	Keys    []string `json:"keys" yaml:"keys"`
+	Pattern string   `json:"pattern" yaml:"pattern"`
+	// Luhn accepts only Pattern matches passing the Luhn checksum.
+	Luhn bool `json:"luhn" yaml:"luhn"`
+	// Mode is "full", "partial" or "hash".
+	Mode string `json:"mode" yaml:"mode"`
+}
+
+// SamplingConfig configures a Sampler.
+type SamplingConfig struct {
+	Tick       string `json:"tick" yaml:"tick"`
+	Initial    int    `json:"initial" yaml:"initial"`
+	Thereafter int    `json:"thereafter" yaml:"thereafter"`
+}
+
+type ConfigError = shared.ConfigError
+
+// LoadConfig reads a YAML or JSON config file, chosen by its extension, and applies
+// the EALOGGER_* environment variables on top of it.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConfigFile(path, data)
+}
+
+// configFormat returns the format of a config file, chosen by its extension.
+func configFormat(path string) ConfigFormat {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ConfigJSON
+	}
+
+	return ConfigYAML
+}
+
+// ParseConfig decodes a config. Unknown keys are reported as errors.
+func ParseConfig(data []byte, format ConfigFormat) (*Config, error) {
+	cfg := &Config{}
+
+	switch format {
+	case ConfigJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, err
+		}
+	case ConfigYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown config format %q", format)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the config and returns all problems found, each as a *ConfigError.
+// Adapter options are checked by the factory of their type when the adapters are built.
+func (c *Config) Validate() error {
+	var errs shared.ConfigErrors
+
+	if c.Level != "" {
+		_, err := ParseLevelSpec(c.Level)
+		errs.Add("level", err)
+	}
+	if c.Stacktrace != "" {
+		_, err := shared.ParseLevel(c.Stacktrace)
+		errs.Add("stacktrace", err)
+	}
+
+	names := make(map[string]int, len(c.Adapters))
+	for i := range c.Adapters {
+		path := fmt.Sprintf("adapters[%d]", i)
+		c.Adapters[i].validate(path, &errs)
+
+		name := c.Adapters[i].name()
+		if first, ok := names[name]; ok {
+			errs.Addf(path+".name", "duplicate adapter name %q, already used by adapters[%d]", name, first)
+		} else {
+			names[name] = i
+		}
+	}
+
+	if c.Redaction != nil {
+		c.Redaction.validate("redaction", &errs)
+	}
+	if c.Sampling != nil {
+		c.Sampling.validate("sampling", &errs)
+	}
+
+	return errs.Err()
+}
+
+func (c *AdapterConfig) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	return c.Type
+}
+
+func (c *AdapterConfig) validate(path string, errs *shared.ConfigErrors) {
+	if c.Level != "" {
+		_, err := shared.ParseLevel(c.Level)
+		errs.Add(path+".level", err)
+	}
+
+	if c.Type == "" {
+		errs.Addf(path+".type", "required")
+	} else if _, ok := adapters.LookupType(c.Type); !ok {
+		errs.Addf(path+".type", "unknown adapter type %q", c.Type)
+	}
+}
+
+func (c *RedactionConfig) validate(path string, errs *shared.ConfigErrors) {
+	for i, rule := range c.Rules {
+		rulePath := fmt.Sprintf("%s.rules[%d]", path, i)
+
+		if len(rule.Keys) == 0 && rule.Pattern == "" {
+			errs.Addf(rulePath, "keys or pattern is required")
+		}
+
+		if rule.Pattern != "" {
+			_, err := regexp.Compile(rule.Pattern)
+			errs.Add(rulePath+".pattern", err)
+		}
+
+		for j, key := range rule.Keys {
+			errs.Add(fmt.Sprintf("%s.keys[%d]", rulePath, j), checkKeyPattern(key))
+		}
+
+		_, err := parseRedactMode(rule.Mode)
+		errs.Add(rulePath+".mode", err)
+	}
+}
+
+func (c *SamplingConfig) validate(path string, errs *shared.ConfigErrors) {
+	if c.Tick == "" {
+		errs.Addf(path+".tick", "required")
+	} else {
+		validateDuration(path+".tick", c.Tick, errs)
+	}
+
+	if c.Initial < 0 {
+		errs.Addf(path+".initial", "must not be negative")
+	}
+	if c.Thereafter < 0 {
+		errs.Addf(path+".thereafter", "must not be negative")
+	}
+}
+
+func validateDuration(path, value string, errs *shared.ConfigErrors) {
+	errs.Duration(path, value)
+}
+
+func parseRedactMode(mode string) (RedactMode, error) {
+	switch mode {
+	case "", "full":
+		return RedactFull, nil
+	case "partial":
+		return RedactPartial, nil
+	case "hash":
+		return RedactHash, nil
+	default:
+		return 0, fmt.Errorf("unknown mode %q", mode)
+	}
+}
+
+// parseDuration returns the duration of a validated value, zero if empty.
+func parseDuration(value string) time.Duration {
+	duration, _ := time.ParseDuration(value)
+	return duration
+}
+
+// parseLevel returns the level of a validated value, or fallback if empty.
+func parseLevel(value string, fallback shared.Level) shared.Level {
+	if value == "" {
+		return fallback
+	}
+
+	level, _ := shared.ParseLevel(value)
+	return level
+}
+
+// build creates the adapter with the factory registered for its type. The options can be
+// overridden by EALOGGER_ADAPTERS_<index>_OPTIONS_* environment variables.
+func (c *AdapterConfig) build(index int) (adapters.Adapter, error) {
+	path := fmt.Sprintf("adapters[%d].options", index)
+	name := fmt.Sprintf("%s_ADAPTERS_%d_OPTIONS", configEnvPrefix, index)
+
+	adapter, err := adapters.New(&adapters.RawConfig{
+		Name:    c.name(),
+		Type:    c.Type,
+		Level:   parseLevel(c.Level, shared.DebugLevel),
+		Options: c.Options,
+		Path:    path,
+		DecodeHook: func(v any) error {
+			return loadEnv(reflect.ValueOf(v).Elem(), name, path)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Names) > 0 || len(c.ExcludeNames) > 0 {
+		return NewRouteAdapter(adapter, c.Names, c.ExcludeNames), nil
+	}
+
+	return adapter, nil
+}
+
+func (c *RedactionConfig) build() *Redactor {
+	cfg := &RedactConfig{HashKey: []byte(c.HashKey)}
+	if c.Defaults {
+		cfg.Rules = defaultRedactConfig(cfg.HashKey).Rules
+	}
+
+	for _, source := range c.Rules {
+		rule := RedactRule{Keys: source.Keys}
+		rule.Mode, _ = parseRedactMode(source.Mode)
+
+		if source.Pattern != "" {
+			rule.Pattern = regexp.MustCompile(source.Pattern)
+		}
+		if source.Luhn {
+			rule.Validate = LuhnValid
+		}
+
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+
+	return NewRedactor(cfg)
+}
+
+// preparedConfig holds everything a Config needs, built before any of it is applied.
+type preparedConfig struct {
+	prev, cfg *Config
+
+	adapters []adapters.Adapter
+	// reused marks the adapters taken over from the logger rather than built.
+	reused   []bool
+	settings settings
+}
+
+// errAdaptersChanged is returned by publishConfig when an adapter the prepared config
+// reuses was replaced or removed since, e.g. by AddAdapter. The config is not applied.
+var errAdaptersChanged = errors.New("ealogger: adapters changed while the config was prepared")
+
+// applyConfig makes the logger match cfg. prev is the config the logger was built from, if any:
+// adapters whose config did not change are kept, the others are rebuilt or closed.
+// Adapters not described by prev are kept as well. Nothing is changed if an adapter fails
+// to build; the error returned after that only reports replaced adapters failing to close.
+func (l *Logger) applyConfig(prev, cfg *Config) error {
+	prepared, err := l.prepareConfig(prev, cfg)
+	if err != nil {
+		return err
+	}
+
+	return l.publishConfig(prepared)
+}
+
+// prepareConfig builds the adapters and settings of cfg without changing the logger.
+func (l *Logger) prepareConfig(prev, cfg *Config) (*preparedConfig, error) {
+	old := l.snapshot()
+
+	built := make([]adapters.Adapter, len(cfg.Adapters))
+	reused := make([]bool, len(cfg.Adapters))
+	var errs []error
+	for i := range cfg.Adapters {
+		source := &cfg.Adapters[i]
+		if prev != nil {
+			if previous := prev.adapter(source.name()); previous != nil && reflect.DeepEqual(previous, source) {
+				if adapter := old.get(source.name()); adapter != nil {
+					built[i], reused[i] = adapter, true
+					continue
+				}
+			}
+		}
+
+		adapter, err := source.build(i)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		built[i] = adapter
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		for _, adapter := range built {
+			if closer, ok := adapter.(interface{ Close() error }); ok && !old.contains(adapter) {
+				_ = closer.Close()
+			}
+		}
+
+		return nil, err
+	}
+
+	prepared := &preparedConfig{
+		prev:     prev,
+		cfg:      cfg,
+		adapters: built,
+		reused:   reused,
+		settings: settings{
+			stacktraceLevel: parseLevel(cfg.Stacktrace, shared.UnselectedLevel),
+			base:            cfg.baseFields(),
+			// The sampler is kept if unchanged, since rebuilding it resets its counters.
+			sampler: old.sampler,
+		},
+	}
+
+	if cfg.Level != "" {
+		prepared.settings.levels, _ = ParseLevelSpec(cfg.Level)
+	}
+
+	if cfg.Redaction != nil {
+		prepared.settings.redactor = cfg.Redaction.build()
+	}
+
+	if prev == nil || !reflect.DeepEqual(prev.Sampling, cfg.Sampling) {
+		prepared.settings.sampler = nil
+		if cfg.Sampling != nil {
+			prepared.settings.sampler = NewSampler(parseDuration(cfg.Sampling.Tick), cfg.Sampling.Initial, cfg.Sampling.Thereafter)
+		}
+	}
+
+	return prepared, nil
+}
+
+// publishConfig replaces the adapters and settings of the logger with the prepared ones
+// in one step, so no log sees a mix of the old and new config. If a reused adapter is no
+// longer installed, nothing is changed, the built adapters are closed and the error wraps
+// errAdaptersChanged.
+func (l *Logger) publishConfig(prepared *preparedConfig) error {
+	prev, cfg := prepared.prev, prepared.cfg
+
+	err := l.swapAdapters(func(old *adapterSet) (*adapterSet, error) {
+		for i, adapter := range prepared.adapters {
+			if prepared.reused[i] && !old.contains(adapter) {
+				return nil, fmt.Errorf("%w: adapter %q", errAdaptersChanged, cfg.Adapters[i].name())
+			}
+		}
+
+		set := &adapterSet{settings: prepared.settings}
+
+		for i, name := range old.names {
+			if prev == nil || prev.adapter(name) == nil && cfg.adapter(name) == nil {
+				set.add(name, old.adapters[i])
+			}
+		}
+
+		for i := range cfg.Adapters {
+			set.add(cfg.Adapters[i].name(), prepared.adapters[i])
+		}
+
+		return set, nil
+	})
+	if errors.Is(err, errAdaptersChanged) {
+		for i, adapter := range prepared.adapters {
+			if closer, ok := adapter.(interface{ Close() error }); ok && !prepared.reused[i] {
+				_ = closer.Close()
+			}
+		}
+	}
+
+	return err
+}
+
+func (c *Config) baseFields() []Field {
+	keys := make([]string, 0, len(c.Fields))
+	for key := range c.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(keys)+5)
+	for _, key := range keys {
+		fields = append(fields, String(key, c.Fields[key]))
+	}
+
+	if c.Process {
+		fields = appendBaseFields(fields, []Field{String("hostname", shared.Hostname()), Int("pid", os.Getpid())}, false)
+	}
+	if c.BuildInfo {
+		fields = appendBaseFields(fields, buildInfoFields(), false)
+	}
+
+	return fields
+}
+
+// adapter returns the config of the adapter named name, nil if there is none.
+func (c *Config) adapter(name string) *AdapterConfig {
+	for i := range c.Adapters {
+		if c.Adapters[i].name() == name {
+			return &c.Adapters[i]
+		}
+	}
+
+	return nil
+}
+
+// Diff describes the changes from c to other, one per entry, such as
+// `adapter "graylog" added` or `level: "info" -> "debug"`.
+func (c *Config) Diff(other *Config) []string {
+	var changes []string
+
+	if c.Level != other.Level {
+		changes = append(changes, fmt.Sprintf("level: %q -> %q", c.Level, other.Level))
+	}
+	if c.Stacktrace != other.Stacktrace {
+		changes = append(changes, fmt.Sprintf("stacktrace: %q -> %q", c.Stacktrace, other.Stacktrace))
+	}
+
+	for i := range c.Adapters {
+		name := c.Adapters[i].name()
+		if changed := other.adapter(name); changed == nil {
+			changes = append(changes, fmt.Sprintf("adapter %q removed", name))
+		} else if !reflect.DeepEqual(&c.Adapters[i], changed) {
+			changes = append(changes, fmt.Sprintf("adapter %q changed", name))
+		}
+	}
+	for i := range other.Adapters {
+		if name := other.Adapters[i].name(); c.adapter(name) == nil {
+			changes = append(changes, fmt.Sprintf("adapter %q added", name))
+		}
+	}
+
+	if !reflect.DeepEqual(c.Fields, other.Fields) || c.Process != other.Process || c.BuildInfo != other.BuildInfo {
+		changes = append(changes, "fields changed")
+	}
+	if !reflect.DeepEqual(c.Redaction, other.Redaction) {
+		changes = append(changes, "redaction changed")
+	}
+	if !reflect.DeepEqual(c.Sampling, other.Sampling) {
+		changes = append(changes, "sampling changed")
+	}
+
+	return changes
+}
+
+// NewFromConfig validates cfg and builds a Logger from it.
+func NewFromConfig(cfg *Config) (*Logger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	l := NewLogger()
+	if err := l.applyConfig(nil, cfg); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
@@ -0,0 +1,204 @@
+package ealogger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const configEnvPrefix = "EALOGGER"
+
+// LoadEnv overrides the config with EALOGGER_* environment variables. Variable names
+// are the upper-cased key path joined with underscores, list items are selected by
+// index, and lists and maps are comma-separated:
+//
+//	EALOGGER_LEVEL=info,db=debug
+//	EALOGGER_ADAPTERS_0_TYPE=console
+//	EALOGGER_ADAPTERS_1_OPTIONS_ADDRESS=graylog:12201
+//	EALOGGER_ADAPTERS_1_OPTIONS_HTTP_HEADERS=X-Token=secret,X-Env=prod
+func (c *Config) LoadEnv() error {
+	return loadEnv(reflect.ValueOf(c).Elem(), configEnvPrefix, "")
+}
+
+// ConfigFromEnv returns a config built only from EALOGGER_* environment variables.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{}
+	if err := cfg.LoadEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func loadEnv(v reflect.Value, name, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		// Pointers to scalars are set from the variable itself, see setEnvValue.
+		if v.Type().Elem().Kind() != reflect.Struct {
+			break
+		}
+		if !hasEnvPrefix(name + "_") {
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return loadEnv(v.Elem(), name, path)
+	case reflect.Struct:
+		var errs []error
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+
+			key := configKey(v.Type().Field(i))
+			errs = append(errs, loadEnv(v.Field(i), name+"_"+strings.ToUpper(key), joinConfigPath(path, key)))
+		}
+
+		return errors.Join(errs...)
+	case reflect.Map:
+		// Raw subtrees, such as adapter options, are overridden once they are decoded.
+		if v.Type().Elem().Kind() == reflect.Interface {
+			return nil
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			break
+		}
+
+		var errs []error
+		for i := 0; i < v.Len() || hasEnvPrefix(fmt.Sprintf("%s_%d_", name, i)); i++ {
+			if i == v.Len() {
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			}
+			errs = append(errs, loadEnv(v.Index(i), fmt.Sprintf("%s_%d", name, i), fmt.Sprintf("%s[%d]", path, i)))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	if err := setEnvValue(v, value); err != nil {
+		return &ConfigError{Path: path, Err: fmt.Errorf("%s: %w", name, err)}
+	}
+
+	return nil
+}
+
+// setEnvValue parses value into v. Lists and maps are comma-separated and each of their
+// items is parsed into the element type; values that do not fit return an error.
+func setEnvValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setEnvValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Slice:
+		items := splitEnvList(value)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setEnvValue(slice.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, item := range splitEnvList(value) {
+			key, itemValue, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value, got %q", item)
+			}
+
+			mapKey := reflect.New(v.Type().Key()).Elem()
+			if err := setEnvValue(mapKey, strings.TrimSpace(key)); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			mapValue := reflect.New(v.Type().Elem()).Elem()
+			if err := setEnvValue(mapValue, strings.TrimSpace(itemValue)); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			m.SetMapIndex(mapKey, mapValue)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func splitEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func hasEnvPrefix(prefix string) bool {
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// configKey returns the key of a config struct field, as used in JSON.
+func configKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if key == "" {
+		return field.Name
+	}
+
+	return key
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
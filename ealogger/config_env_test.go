@@ -0,0 +1,65 @@
+package ealogger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type envTestOptions struct {
+	Ports   []int             `json:"ports"`
+	Names   []string          `json:"names"`
+	Retries *int              `json:"retries"`
+	Gzip    *bool             `json:"gzip"`
+	Weights map[string]uint16 `json:"weights"`
+	Nested  *struct {
+		Ratio float64 `json:"ratio"`
+	} `json:"nested"`
+}
+
+func TestLoadEnv_ConvertsElementsAndPointers(t *testing.T) {
+	t.Setenv("EALOGGER_PORTS", "80, 443")
+	t.Setenv("EALOGGER_NAMES", "api,db")
+	t.Setenv("EALOGGER_RETRIES", "3")
+	t.Setenv("EALOGGER_GZIP", "true")
+	t.Setenv("EALOGGER_WEIGHTS", "a=1,b=2")
+	t.Setenv("EALOGGER_NESTED_RATIO", "0.5")
+
+	var options envTestOptions
+	if err := loadEnv(reflect.ValueOf(&options).Elem(), configEnvPrefix, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(options.Ports, []int{80, 443}) || !reflect.DeepEqual(options.Names, []string{"api", "db"}) {
+		t.Fatalf("unexpected lists %v %v", options.Ports, options.Names)
+	}
+	if options.Retries == nil || *options.Retries != 3 || options.Gzip == nil || !*options.Gzip {
+		t.Fatalf("unexpected pointers %v %v", options.Retries, options.Gzip)
+	}
+	if !reflect.DeepEqual(options.Weights, map[string]uint16{"a": 1, "b": 2}) {
+		t.Fatalf("unexpected map %v", options.Weights)
+	}
+	if options.Nested == nil || options.Nested.Ratio != 0.5 {
+		t.Fatalf("unexpected nested struct %v", options.Nested)
+	}
+}
+
+func TestLoadEnv_InvalidValues(t *testing.T) {
+	for name, value := range map[string]string{
+		"EALOGGER_PORTS":   "80,https",
+		"EALOGGER_RETRIES": "many",
+		"EALOGGER_WEIGHTS": "a=-1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+
+			var options envTestOptions
+			err := loadEnv(reflect.ValueOf(&options).Elem(), configEnvPrefix, "")
+
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("expected a ConfigError, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package ealogger
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type ConfigWatcherConfig struct {
+	// Interval is how often the file is checked for changes.
+	Interval time.Duration
+	// Signals trigger a reload when received.
+	Signals []os.Signal
+}
+
+// ConfigWatcher reloads the Logger built from a config file when the file changes or
+// a signal such as SIGHUP is received. A config that fails to load or validate is
+// reported through the logger and the current one is kept.
+type ConfigWatcher struct {
+	l    *Logger
+	path string
+	cfg  *ConfigWatcherConfig
+
+	mu      sync.Mutex
+	current *Config
+	sum     [sha256.Size]byte
+	modTime time.Time
+	size    int64
+
+	signals   chan os.Signal
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (w *ConfigWatcher) Logger() *Logger {
+	return w.l
+}
+
+// Config returns the config currently applied to the logger.
+func (w *ConfigWatcher) Config() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Reload reads the config file and applies it if it changed. Adapters that were removed
+// or changed are closed once the logs being written to them are done.
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.reload(true)
+}
+
+func (w *ConfigWatcher) reload(force bool) error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return w.reloadFailed(err)
+	}
+	if !force && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return w.reloadFailed(err)
+	}
+
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	sum := sha256.Sum256(data)
+	if sum == w.sum && !force {
+		return nil
+	}
+
+	cfg, err := parseConfigFile(w.path, data)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return w.reloadFailed(err)
+	}
+
+	changes := w.current.Diff(cfg)
+	if len(changes) == 0 {
+		w.sum = sum
+		return nil
+	}
+
+	prepared, err := w.l.prepareConfig(w.current, cfg)
+	if err != nil {
+		return w.reloadFailed(err)
+	}
+
+	// The new config is in effect from here on, even if a replaced adapter fails to close.
+	closeErr := w.l.publishConfig(prepared)
+	if errors.Is(closeErr, errAdaptersChanged) {
+		// The file is read again on the next check.
+		w.modTime, w.size = time.Time{}, 0
+		return w.reloadFailed(closeErr)
+	}
+	w.current, w.sum = cfg, sum
+
+	logger := w.l.Named("ealogger")
+	if closeErr != nil {
+		logger.WithError(closeErr).Warnw("config reloaded, closing replaced adapters failed", String("path", w.path), Any("changes", changes))
+		return closeErr
+	}
+
+	logger.Infow("config reloaded", String("path", w.path), Any("changes", changes))
+
+	return nil
+}
+
+func (w *ConfigWatcher) reloadFailed(err error) error {
+	err = fmt.Errorf("reload %s: %w", w.path, err)
+	w.l.Named("ealogger").WithError(err).Errorw("config reload failed, keeping the current config", String("path", w.path))
+
+	return err
+}
+
+func (w *ConfigWatcher) watch() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			_ = w.reload(false)
+			w.mu.Unlock()
+		case <-w.signals:
+			_ = w.Reload()
+		}
+	}
+}
+
+// Close stops watching. The logger keeps its current config. Calling it again has no effect.
+func (w *ConfigWatcher) Close() error {
+	w.closeOnce.Do(func() {
+		if w.signals != nil {
+			signal.Stop(w.signals)
+		}
+
+		close(w.stop)
+	})
+	<-w.done
+
+	return nil
+}
+
+// parseConfigFile parses a config file like LoadConfig does.
+func parseConfigFile(path string, data []byte) (*Config, error) {
+	cfg, err := ParseConfig(data, configFormat(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := cfg.LoadEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// NewConfigWatcher loads the config file at path, builds a Logger from it with
+// NewFromConfig and watches the file for changes. cfg is required and its Interval
+// must be positive; use NewDefaultConfigWatcher for the defaults.
+func NewConfigWatcher(path string, cfg *ConfigWatcherConfig) (*ConfigWatcher, error) {
+	if cfg == nil {
+		return nil, errors.New("ealogger: config watcher config is required")
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("ealogger: config watcher interval must be positive, got %s", cfg.Interval)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := parseConfigFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := NewFromConfig(current)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ConfigWatcher{
+		l:       l,
+		path:    path,
+		cfg:     cfg,
+		current: current,
+		sum:     sha256.Sum256(data),
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if len(cfg.Signals) > 0 {
+		w.signals = make(chan os.Signal, 1)
+		signal.Notify(w.signals, cfg.Signals...)
+	}
+
+	go w.watch()
+
+	return w, nil
+}
+
+func NewDefaultConfigWatcher(path string) (*ConfigWatcher, error) {
+	return NewConfigWatcher(path, defaultConfigWatcherConfig())
+}
+
+func defaultConfigWatcherConfig() *ConfigWatcherConfig {
+	return &ConfigWatcherConfig{
+		Interval: time.Second,
+		Signals:  []os.Signal{syscall.SIGHUP},
+	}
+}
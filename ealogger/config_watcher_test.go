@@ -0,0 +1,71 @@
+package ealogger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const watcherTestConfig = `
+level: info
+adapters:
+  - type: console
+    options:
+      writer: stderr
+`
+
+func TestLogger_PublishConfigAfterAdapterRemoved(t *testing.T) {
+	prev, err := ParseConfig([]byte(watcherTestConfig), ConfigYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := ParseConfig([]byte(watcherTestConfig), ConfigYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.Level = "debug"
+
+	l, err := NewFromConfig(prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prepared, err := l.prepareConfig(prev, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RemoveAdapter("console"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.publishConfig(prepared); !errors.Is(err, errAdaptersChanged) {
+		t.Fatalf("expected errAdaptersChanged, got %v", err)
+	}
+	if _, ok := l.Adapter("console"); ok {
+		t.Fatal("expected the removed adapter not to be reinstalled")
+	}
+	if levels := l.Levels(); levels == nil || levels.String() != "info" {
+		t.Fatalf("expected the config not to be applied, got levels %v", levels)
+	}
+}
+
+func TestConfigWatcher_CloseTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ealogger.yaml")
+	if err := os.WriteFile(path, []byte(watcherTestConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewConfigWatcher(path, &ConfigWatcherConfig{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
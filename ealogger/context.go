@@ -0,0 +1,56 @@
+package ealogger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type entryContextKey struct{}
+
+type requestIDContextKey struct{}
+
+// NewContext returns a copy of ctx that carries entry.
+func NewContext(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, entryContextKey{}, entry)
+}
+
+// FromContext returns a copy of the entry stored in ctx by NewContext, or nil if there is none.
+// Entries are not safe for concurrent use, so every call returns a new one: WithField,
+// WithError and the like on the result do not affect the stored entry or other callers.
+func FromContext(ctx context.Context) *Entry {
+	entry, _ := ctx.Value(entryContextKey{}).(*Entry)
+	if entry == nil {
+		return nil
+	}
+
+	return entry.Bind(nil)
+}
+
+// FromContextOr returns a copy of the entry stored in ctx, or a new entry of l if there is none.
+func FromContextOr(ctx context.Context, l *Logger) *Entry {
+	if entry := FromContext(ctx); entry != nil {
+		return entry
+	}
+
+	return NewEntry(l)
+}
+
+// ContextWithRequestID returns a copy of ctx that carries the request ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// NewRequestID returns a random 128-bit request ID encoded as hex.
+func NewRequestID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+
+	return hex.EncodeToString(id[:])
+}
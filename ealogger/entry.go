@@ -1,23 +1,63 @@
 package ealogger
 
 import (
-	"encoding/json"
 	"github.com/eris-apple/ealogger/ealogger/shared"
 )
 
 type Entry struct {
 	l    *Logger
 	data *shared.LogData
+
+	// bound and typed hold fields added to every log of the entry, see Bind and With.
+	bound shared.LogField
+	typed []shared.Field
 }
 
 func (e *Entry) Log(log shared.Log) {
+	if !e.IsEnabled(log.Level) {
+		e.reset()
+		shared.ReleaseLog(log)
+		return
+	}
+
+	own := log.Data
 	log.Data = e.data
+	if len(e.bound) > 0 || len(e.typed) > 0 || (own != nil && (len(own.TypedFields) > 0 || own.Error != nil || own.Multiline)) {
+		data := *e.data
+		data.TypedFields = e.typed
+
+		if len(e.bound) > 0 {
+			data.Fields = make(shared.LogField, len(e.bound)+len(e.data.Fields))
+			for key, value := range e.bound {
+				data.Fields[key] = value
+			}
+			for key, value := range e.data.Fields {
+				data.Fields[key] = value
+			}
+		}
+
+		if own != nil {
+			data.TypedFields = appendFields(e.typed, own.TypedFields)
+			data.Error = shared.AppendError(data.Error, own.Error)
+			data.Multiline = own.Multiline
+		}
+
+		log.Data = &data
+	}
+
 	e.l.Log(log)
+	e.reset()
 
-	defer func() {
-		e.data.Error = nil
-		e.data.Fields = nil
-	}()
+	if own != nil {
+		shared.ReleaseLog(shared.Log{Data: own})
+	}
+}
+
+// reset clears the fields and error set for a single log.
+func (e *Entry) reset() {
+	e.data.Error = nil
+	e.data.Fields = nil
+	e.data.Stack = nil
 }
 
 func (e *Entry) WithField(field shared.LogField) *Entry {
@@ -32,6 +72,38 @@ func (e *Entry) WithFields(Fields shared.LogField) *Entry {
 	return e
 }
 
+// Bind returns a new entry with the same name that adds fields to every log.
+// Unlike WithFields, bound fields are kept after logging and the receiver is not modified,
+// so the returned entry can be shared, e.g. through a context.
+func (e *Entry) Bind(fields shared.LogField) *Entry {
+	var bound shared.LogField
+	if len(e.bound) > 0 || len(fields) > 0 {
+		bound = make(shared.LogField, len(e.bound)+len(fields))
+		for key, value := range e.bound {
+			bound[key] = value
+		}
+		for key, value := range fields {
+			bound[key] = value
+		}
+	}
+
+	entry := NewEntry(e.l)
+	entry.data.TraceName = e.data.TraceName
+	entry.data.WithName = e.data.WithName
+	entry.bound = bound
+	entry.typed = e.typed
+
+	return entry
+}
+
+// With returns a new entry that adds the typed fields to every log, see Bind.
+func (e *Entry) With(fields ...shared.Field) *Entry {
+	entry := e.Bind(nil)
+	entry.typed = appendFields(e.typed, fields)
+
+	return entry
+}
+
 func (e *Entry) WithName(traceName string) *Entry {
 	e.data.TraceName = traceName
 	e.data.WithName = true
@@ -46,70 +118,233 @@ func (e *Entry) ClearName() *Entry {
 	return e
 }
 
+// WithError attaches err to the entry. Errors attached more than once are combined with errors.Join.
 func (e *Entry) WithError(err error) *Entry {
-	e.data.Error = err
+	e.data.Error = shared.AppendError(e.data.Error, err)
+
+	return e
+}
+
+func (e *Entry) WithErrors(errs ...error) *Entry {
+	for _, err := range errs {
+		e.WithError(err)
+	}
 
 	return e
 }
 
 func (e *Entry) Print(args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.UnselectedLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLog(shared.UnselectedLevel, args...))
 }
 
 func (e *Entry) Printf(format string, args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.UnselectedLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLogf(shared.UnselectedLevel, format, args...))
 }
 
 func (e *Entry) Info(args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.InfoLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLog(shared.InfoLevel, args...))
 }
 
 func (e *Entry) Infof(format string, args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.InfoLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLogf(shared.InfoLevel, format, args...))
 }
 
+func (e *Entry) Infow(msg string, fields ...shared.Field) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.InfoLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewFieldsLog(shared.InfoLevel, msg, fields))
+}
+
+func (e *Entry) Trace(args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.TraceLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewDefaultLog(shared.TraceLevel, args...))
+}
+
+func (e *Entry) Tracef(format string, args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.TraceLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewDefaultLogf(shared.TraceLevel, format, args...))
+}
+
+func (e *Entry) Tracew(msg string, fields ...shared.Field) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.TraceLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewFieldsLog(shared.TraceLevel, msg, fields))
+}
+
 func (e *Entry) Debug(args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.DebugLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLog(shared.DebugLevel, args...))
 }
 
 func (e *Entry) Debugf(format string, args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.DebugLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLogf(shared.DebugLevel, format, args...))
 }
 
+func (e *Entry) Debugw(msg string, fields ...shared.Field) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.DebugLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewFieldsLog(shared.DebugLevel, msg, fields))
+}
+
 func (e *Entry) DebugJSON(data interface{}) {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.DebugLevel) {
+		e.reset()
+		return
+	}
+
+	jsonData, err := e.l.marshalJSON(data)
 	if err != nil {
 		e.Log(shared.NewDefaultLog(shared.DebugLevel, "error with marshaling struct"))
 		return
 	}
 
-	e.Log(shared.NewDefaultLog(shared.DebugLevel, string(jsonData)))
+	log := shared.NewDefaultLog(shared.DebugLevel, string(jsonData))
+	log.Data.Multiline = true
+	e.Log(log)
 }
 
 func (e *Entry) Warn(args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.WarnLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLog(shared.WarnLevel, args...))
 }
 
 func (e *Entry) Warnf(format string, args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.WarnLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLogf(shared.WarnLevel, format, args...))
 }
 
+func (e *Entry) Warnw(msg string, fields ...shared.Field) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.WarnLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewFieldsLog(shared.WarnLevel, msg, fields))
+}
+
 func (e *Entry) Error(args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.ErrorLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLog(shared.ErrorLevel, args...))
 }
 
 func (e *Entry) Errorf(format string, args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.ErrorLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLogf(shared.ErrorLevel, format, args...))
 }
 
+func (e *Entry) Errorw(msg string, fields ...shared.Field) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.ErrorLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewFieldsLog(shared.ErrorLevel, msg, fields))
+}
+
 func (e *Entry) Fatal(args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.FatalLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLog(shared.FatalLevel, args...))
 }
 
 func (e *Entry) Fatalf(format string, args ...any) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.FatalLevel) {
+		e.reset()
+		return
+	}
+
 	e.Log(shared.NewDefaultLogf(shared.FatalLevel, format, args...))
 }
 
+func (e *Entry) Fatalw(msg string, fields ...shared.Field) {
+	if !e.l.IsNameEnabled(e.data.TraceName, shared.FatalLevel) {
+		e.reset()
+		return
+	}
+
+	e.Log(shared.NewFieldsLog(shared.FatalLevel, msg, fields))
+}
+
+// appendFields returns the concatenation of a and b without modifying either of them.
+func appendFields(a, b []shared.Field) []shared.Field {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+
+	fields := make([]shared.Field, 0, len(a)+len(b))
+	fields = append(fields, a...)
+
+	return append(fields, b...)
+}
+
 func NewEntry(l *Logger) *Entry {
 	return &Entry{
 		l: l,
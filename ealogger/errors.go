@@ -0,0 +1,44 @@
+package ealogger
+
+import (
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+)
+
+// StackError is an error that records the call stack at the point it was created.
+// Adapters render the stack when the error is logged with WithError.
+type StackError struct {
+	err   error
+	stack shared.Stack
+}
+
+func (e *StackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *StackError) Unwrap() error {
+	return e.err
+}
+
+func (e *StackError) StackTrace() shared.Stack {
+	return e.stack
+}
+
+func NewError(message string) error {
+	return &StackError{
+		err:   fmt.Errorf("%s", message),
+		stack: shared.CaptureStack(1),
+	}
+}
+
+// WithStack annotates err with the current call stack. It returns nil if err is nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &StackError{
+		err:   err,
+		stack: shared.CaptureStack(1),
+	}
+}
@@ -0,0 +1,54 @@
+package ealogger
+
+import (
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"time"
+)
+
+type Field = shared.Field
+
+func String(key, value string) Field {
+	return shared.String(key, value)
+}
+
+func Int(key string, value int) Field {
+	return shared.Int(key, value)
+}
+
+func Int64(key string, value int64) Field {
+	return shared.Int64(key, value)
+}
+
+func Float64(key string, value float64) Field {
+	return shared.Float64(key, value)
+}
+
+func Bool(key string, value bool) Field {
+	return shared.Bool(key, value)
+}
+
+func Duration(key string, value time.Duration) Field {
+	return shared.Duration(key, value)
+}
+
+func Time(key string, value time.Time) Field {
+	return shared.Time(key, value)
+}
+
+// Err attaches err to the log the same way as WithError.
+func Err(err error) Field {
+	return shared.Err(err)
+}
+
+func Stringer(key string, value fmt.Stringer) Field {
+	return shared.Stringer(key, value)
+}
+
+func Object(key string, value shared.ObjectMarshaler) Field {
+	return shared.Object(key, value)
+}
+
+func Any(key string, value any) Field {
+	return shared.Any(key, value)
+}
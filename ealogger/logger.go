@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"github.com/eris-apple/ealogger/ealogger/adapters"
 	"github.com/eris-apple/ealogger/ealogger/shared"
+	"sync"
+	"sync/atomic"
 )
 
 type Mode = string
@@ -39,14 +41,108 @@ type EALogger interface {
 }
 
 type Logger struct {
-	adapters []adapters.Adapter
+	// adapters is replaced as a whole when adapters or settings change, see swapAdapters.
+	adapters atomic.Pointer[adapterSet]
+	// mu serializes changes of the adapters.
+	mu sync.Mutex
 }
 
+// Log writes log to every adapter that accepts its level. Adapters receive a shallow copy
+// of log.Data; Fields and TypedFields are shared between them and must not be modified.
+// Logs created with the shared.NewDefaultLog family are released to a pool afterwards
+// and must not be used once Log returns.
 func (l *Logger) Log(log shared.Log) {
-	for _, adapter := range l.adapters {
-		logCopy := shared.NewLogCopy(log)
-		adapter.Log(logCopy)
+	defer shared.ReleaseLog(log)
+
+	set := l.acquireAdapters()
+	defer set.mu.RUnlock()
+
+	if !set.isNameEnabled(log.Data.TraceName, log.Level) {
+		return
+	}
+
+	if set.sampler != nil && !set.sampler.Sample(log) {
+		return
+	}
+
+	stack := set.stacktrace(log)
+
+	if errorFields := shared.ErrorFields(log.Data.Error); len(errorFields) > 0 {
+		fields := make(shared.LogField, len(log.Data.Fields)+len(errorFields))
+		for key, value := range errorFields {
+			fields[key] = value
+		}
+		for key, value := range log.Data.Fields {
+			fields[key] = value
+		}
+
+		// The fields of log are shared with its creator, so they are replaced rather than modified.
+		merged := *log.Data
+		merged.Fields = fields
+		log.Data = &merged
+	}
+
+	if len(set.base) > 0 {
+		data := *log.Data
+		data.TypedFields = mergeBaseFields(set.base, &data)
+		log.Data = &data
 	}
+
+	// LogValuers are resolved once here rather than by every adapter.
+	fields, resolvedFields := shared.ResolveFields(log.Data.Fields)
+	typed, resolvedTyped := shared.ResolveTypedFields(log.Data.TypedFields)
+	if resolvedFields || resolvedTyped {
+		data := *log.Data
+		data.Fields = fields
+		data.TypedFields = typed
+		log.Data = &data
+	}
+
+	if set.redactor != nil {
+		log = set.redactor.Redact(log)
+	}
+
+	for _, adapter := range set.adapters {
+		if enabler, ok := adapter.(adapters.LevelEnabler); ok && !enabler.IsEnabled(log.Level) {
+			continue
+		}
+
+		view := shared.NewLogView(log)
+		view.Data.Stack = stack
+		adapter.Log(view)
+		shared.ReleaseLog(view)
+	}
+}
+
+// IsEnabled reports whether at least one adapter accepts logs of the given level.
+// Adapters that do not implement adapters.LevelEnabler accept every level. The adapters
+// are asked on every call, so changes of their level or Enable take effect immediately.
+func (l *Logger) IsEnabled(level shared.Level) bool {
+	return l.snapshot().isEnabled(level)
+}
+
+// SetStacktraceLevel enables capturing of the call stack for logs at or above the given level.
+// Stacks carried by logged errors are always used. UnselectedLevel disables capturing.
+func (l *Logger) SetStacktraceLevel(level shared.Level) {
+	l.updateSettings(func(s *settings) {
+		s.stacktraceLevel = level
+	})
+}
+
+func (s *adapterSet) stacktrace(log shared.Log) shared.Stack {
+	if log.Data.Stack != nil {
+		return log.Data.Stack
+	}
+
+	if stack := shared.StackFromError(log.Data.Error); stack != nil {
+		return stack
+	}
+
+	if s.stacktraceLevel == shared.UnselectedLevel || log.Level == shared.UnselectedLevel || !s.stacktraceLevel.IsEnabled(log.Level) {
+		return nil
+	}
+
+	return shared.CaptureStack(1).From(log.PC)
 }
 
 func (l *Logger) WithFields(fields shared.LogField) *Entry {
@@ -61,6 +157,12 @@ func (l *Logger) WithField(key string, value interface{}) *Entry {
 	return entry
 }
 
+func (l *Logger) With(fields ...shared.Field) *Entry {
+	entry := NewEntry(l)
+	entry.typed = fields
+	return entry
+}
+
 func (l *Logger) WithName(traceName string) *Entry {
 	entry := NewEntry(l)
 	entry.WithName(traceName)
@@ -73,102 +175,272 @@ func (l *Logger) WithError(err error) *Entry {
 	return entry
 }
 
+func (l *Logger) WithErrors(errs ...error) *Entry {
+	entry := NewEntry(l)
+	entry.WithErrors(errs...)
+	return entry
+}
+
 func (l *Logger) Print(args ...any) {
+	if !l.IsNameEnabled("", shared.UnselectedLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLog(shared.UnselectedLevel, args...))
 }
 
 func (l *Logger) Printf(format string, args ...any) {
+	if !l.IsNameEnabled("", shared.UnselectedLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogf(shared.UnselectedLevel, format, args...))
 }
 
 func (l *Logger) Info(args ...any) {
+	if !l.IsNameEnabled("", shared.InfoLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLog(shared.InfoLevel, args...))
 }
 
 func (l *Logger) Infon(traceName string, args ...any) {
+	if !l.IsNameEnabled(traceName, shared.InfoLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogn(shared.InfoLevel, traceName, args...))
 }
 
 func (l *Logger) Infof(format string, args ...any) {
+	if !l.IsNameEnabled("", shared.InfoLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogf(shared.InfoLevel, format, args...))
 }
 
+func (l *Logger) Infow(msg string, fields ...shared.Field) {
+	if !l.IsNameEnabled("", shared.InfoLevel) {
+		return
+	}
+
+	l.Log(shared.NewFieldsLog(shared.InfoLevel, msg, fields))
+}
+
+func (l *Logger) Trace(args ...any) {
+	if !l.IsNameEnabled("", shared.TraceLevel) {
+		return
+	}
+
+	l.Log(shared.NewDefaultLog(shared.TraceLevel, args...))
+}
+
+func (l *Logger) Tracen(traceName string, args ...any) {
+	if !l.IsNameEnabled(traceName, shared.TraceLevel) {
+		return
+	}
+
+	l.Log(shared.NewDefaultLogn(shared.TraceLevel, traceName, args...))
+}
+
+func (l *Logger) Tracef(format string, args ...any) {
+	if !l.IsNameEnabled("", shared.TraceLevel) {
+		return
+	}
+
+	l.Log(shared.NewDefaultLogf(shared.TraceLevel, format, args...))
+}
+
+func (l *Logger) Tracew(msg string, fields ...shared.Field) {
+	if !l.IsNameEnabled("", shared.TraceLevel) {
+		return
+	}
+
+	l.Log(shared.NewFieldsLog(shared.TraceLevel, msg, fields))
+}
+
 func (l *Logger) Debug(args ...any) {
+	if !l.IsNameEnabled("", shared.DebugLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLog(shared.DebugLevel, args...))
 }
 
 func (l *Logger) Debugn(traceName string, args ...any) {
+	if !l.IsNameEnabled(traceName, shared.DebugLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogn(shared.DebugLevel, traceName, args...))
 }
 
 func (l *Logger) Debugf(format string, args ...any) {
+	if !l.IsNameEnabled("", shared.DebugLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogf(shared.DebugLevel, format, args...))
 }
 
+func (l *Logger) Debugw(msg string, fields ...shared.Field) {
+	if !l.IsNameEnabled("", shared.DebugLevel) {
+		return
+	}
+
+	l.Log(shared.NewFieldsLog(shared.DebugLevel, msg, fields))
+}
+
 func (e *Logger) DebugJSON(data interface{}) {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if !e.IsNameEnabled("", shared.DebugLevel) {
+		return
+	}
+
+	jsonData, err := e.marshalJSON(data)
 	if err != nil {
 		e.Log(shared.NewDefaultLog(shared.DebugLevel, "error with marshaling struct"))
 		return
 	}
 
-	e.Log(shared.NewDefaultLog(shared.DebugLevel, string(jsonData)))
+	log := shared.NewDefaultLog(shared.DebugLevel, string(jsonData))
+	log.Data.Multiline = true
+	e.Log(log)
 }
 
-func (e *Logger) DebugnJSON(traceName string, data interface{}) {
+// marshalJSON indents data for DebugJSON, redacting its keys and values if a redactor is set.
+func (l *Logger) marshalJSON(data any) ([]byte, error) {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
+	redactor := l.Redactor()
+	if err != nil || redactor == nil {
+		return jsonData, err
+	}
+
+	return redactor.RedactJSON(jsonData)
+}
+
+func (e *Logger) DebugnJSON(traceName string, data interface{}) {
+	if !e.IsNameEnabled(traceName, shared.DebugLevel) {
+		return
+	}
+
+	jsonData, err := e.marshalJSON(data)
 	if err != nil {
 		e.Log(shared.NewDefaultLogn(shared.DebugLevel, traceName, "error with marshaling struct"))
 		return
 	}
 
-	e.Log(shared.NewDefaultLogn(shared.DebugLevel, traceName, string(jsonData)))
+	log := shared.NewDefaultLogn(shared.DebugLevel, traceName, string(jsonData))
+	log.Data.Multiline = true
+	e.Log(log)
 }
 
 func (l *Logger) Warn(args ...any) {
+	if !l.IsNameEnabled("", shared.WarnLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLog(shared.WarnLevel, args...))
 }
 
 func (l *Logger) Warnn(traceName string, args ...any) {
+	if !l.IsNameEnabled(traceName, shared.WarnLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogn(shared.WarnLevel, traceName, args...))
 }
 
 func (l *Logger) Warnf(format string, args ...any) {
+	if !l.IsNameEnabled("", shared.WarnLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogf(shared.WarnLevel, format, args...))
 }
 
+func (l *Logger) Warnw(msg string, fields ...shared.Field) {
+	if !l.IsNameEnabled("", shared.WarnLevel) {
+		return
+	}
+
+	l.Log(shared.NewFieldsLog(shared.WarnLevel, msg, fields))
+}
+
 func (l *Logger) Error(args ...any) {
+	if !l.IsNameEnabled("", shared.ErrorLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLog(shared.ErrorLevel, args...))
 }
 
 func (l *Logger) Errorn(traceName string, args ...any) {
+	if !l.IsNameEnabled(traceName, shared.ErrorLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogn(shared.ErrorLevel, traceName, args...))
 }
 
 func (l *Logger) Errorf(format string, args ...any) {
+	if !l.IsNameEnabled("", shared.ErrorLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogf(shared.ErrorLevel, format, args...))
 }
 
+func (l *Logger) Errorw(msg string, fields ...shared.Field) {
+	if !l.IsNameEnabled("", shared.ErrorLevel) {
+		return
+	}
+
+	l.Log(shared.NewFieldsLog(shared.ErrorLevel, msg, fields))
+}
+
 func (l *Logger) Fatal(args ...any) {
+	if !l.IsNameEnabled("", shared.FatalLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLog(shared.FatalLevel, args...))
 }
 
 func (l *Logger) Fataln(traceName string, args ...any) {
+	if !l.IsNameEnabled(traceName, shared.FatalLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogn(shared.FatalLevel, traceName, args...))
 }
 
 func (l *Logger) Fatalf(format string, args ...any) {
+	if !l.IsNameEnabled("", shared.FatalLevel) {
+		return
+	}
+
 	l.Log(shared.NewDefaultLogf(shared.FatalLevel, format, args...))
 }
 
+func (l *Logger) Fatalw(msg string, fields ...shared.Field) {
+	if !l.IsNameEnabled("", shared.FatalLevel) {
+		return
+	}
+
+	l.Log(shared.NewFieldsLog(shared.FatalLevel, msg, fields))
+}
+
 func NewLoggerWithMode(mode Mode) *Logger {
 	return NewLogger(setupDefaultLogger(mode)...)
 }
 
 func NewLogger(adapters ...adapters.Adapter) *Logger {
-	return &Logger{
-		adapters: adapters,
-	}
+	l := &Logger{}
+	l.adapters.Store(newAdapterSet(adapters))
+
+	return l
 }
 
 func setupDefaultLogger(mode Mode) (adp []adapters.Adapter) {
@@ -0,0 +1,138 @@
+package logrsink
+
+import (
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"github.com/go-logr/logr"
+	"runtime"
+	"time"
+)
+
+// Sink is a logr.LogSink that writes through an ealogger.Logger.
+// V(0) maps to Info, V(1) to Debug and V(2) and above to Trace.
+type Sink struct {
+	l *ealogger.Logger
+
+	name      string
+	fields    shared.LogField
+	callDepth int
+}
+
+func (s *Sink) Init(info logr.RuntimeInfo) {
+	s.callDepth += info.CallDepth
+}
+
+func (s *Sink) Enabled(level int) bool {
+	return s.l.IsNameEnabled(s.name, VLevel(level))
+}
+
+func (s *Sink) Info(level int, msg string, keysAndValues ...any) {
+	s.log(VLevel(level), nil, msg, keysAndValues)
+}
+
+func (s *Sink) Error(err error, msg string, keysAndValues ...any) {
+	s.log(shared.ErrorLevel, err, msg, keysAndValues)
+}
+
+func (s *Sink) WithValues(keysAndValues ...any) logr.LogSink {
+	clone := s.clone()
+	addKeysAndValues(clone.fields, keysAndValues)
+
+	return clone
+}
+
+// WithName appends name to the trace name, separated by a dot.
+func (s *Sink) WithName(name string) logr.LogSink {
+	clone := s.clone()
+	if clone.name == "" {
+		clone.name = name
+	} else {
+		clone.name = clone.name + "." + name
+	}
+
+	return clone
+}
+
+func (s *Sink) WithCallDepth(depth int) logr.LogSink {
+	clone := s.clone()
+	clone.callDepth += depth
+
+	return clone
+}
+
+func (s *Sink) log(level shared.Level, err error, msg string, keysAndValues []any) {
+	fields := make(shared.LogField, len(s.fields)+len(keysAndValues)/2)
+	for key, value := range s.fields {
+		fields[key] = value
+	}
+	addKeysAndValues(fields, keysAndValues)
+
+	var pcs [1]uintptr
+	runtime.Callers(3+s.callDepth, pcs[:])
+
+	s.l.Log(shared.Log{
+		Level:   level,
+		Message: msg,
+		Data: &shared.LogData{
+			Fields:    fields,
+			Error:     err,
+			TraceName: s.name,
+			WithName:  s.name != "",
+		},
+		Time: time.Now(),
+		PC:   pcs[0],
+	})
+}
+
+func (s *Sink) clone() *Sink {
+	fields := make(shared.LogField, len(s.fields))
+	for key, value := range s.fields {
+		fields[key] = value
+	}
+
+	return &Sink{
+		l:         s.l,
+		name:      s.name,
+		fields:    fields,
+		callDepth: s.callDepth,
+	}
+}
+
+// VLevel maps a logr verbosity onto an ealogger level.
+func VLevel(level int) shared.Level {
+	switch {
+	case level <= 0:
+		return shared.InfoLevel
+	case level == 1:
+		return shared.DebugLevel
+	default:
+		return shared.TraceLevel
+	}
+}
+
+func addKeysAndValues(fields shared.LogField, keysAndValues []any) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		if i+1 < len(keysAndValues) {
+			fields[key] = keysAndValues[i+1]
+		} else {
+			fields[key] = "(MISSING)"
+		}
+	}
+}
+
+func NewSink(l *ealogger.Logger) *Sink {
+	return &Sink{
+		l:      l,
+		fields: make(shared.LogField),
+	}
+}
+
+func New(l *ealogger.Logger) logr.Logger {
+	return logr.New(NewSink(l))
+}
@@ -0,0 +1,316 @@
+package grpclog
+
+import (
+	"context"
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Config struct {
+	// RequestIDHeader is the metadata key used to propagate request IDs.
+	RequestIDHeader string
+	// NewRequestID generates an ID for incoming calls without one.
+	NewRequestID func() string
+
+	TraceName string
+}
+
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx      context.Context
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	}
+
+	return err
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+	}
+
+	return err
+}
+
+type clientStream struct {
+	grpc.ClientStream
+
+	desc     *grpc.StreamDesc
+	sent     atomic.Int64
+	received atomic.Int64
+
+	once     sync.Once
+	finished chan struct{}
+	finish   func(err error)
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	} else if err != io.EOF {
+		s.done(err)
+	}
+
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.received.Add(1)
+		// Without server streaming the single response ends the call;
+		// callers are not required to call RecvMsg again to see io.EOF.
+		if !s.desc.ServerStreams {
+			s.done(nil)
+		}
+	case err == io.EOF:
+		s.done(nil)
+	default:
+		s.done(err)
+	}
+
+	return err
+}
+
+// done logs the call once, whichever of RecvMsg, SendMsg or the context ends it first.
+func (s *clientStream) done(err error) {
+	s.once.Do(func() {
+		close(s.finished)
+		s.finish(err)
+	})
+}
+
+// watch ends the call when ctx is done before the stream finishes on its own.
+func (s *clientStream) watch(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		s.done(status.FromContextError(ctx.Err()).Err())
+	case <-s.finished:
+	}
+}
+
+// UnaryServerInterceptor stores a per-call Entry in the context, logs every call and
+// recovers panics of the handler.
+func UnaryServerInterceptor(l *ealogger.Logger, cfg *Config) grpc.UnaryServerInterceptor {
+	cfg = withDefaults(cfg, "grpc")
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		start := time.Now()
+		ctx, entry := serverContext(ctx, l, cfg)
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recoverPanic(entry, info.FullMethod, rec)
+			}
+
+			logCall(entry, info.FullMethod, err, start, shared.LogField{
+				"peer": peerAddr(ctx),
+			})
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(l *ealogger.Logger, cfg *Config) grpc.StreamServerInterceptor {
+	cfg = withDefaults(cfg, "grpc")
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		ctx, entry := serverContext(ss.Context(), l, cfg)
+		stream := &serverStream{ServerStream: ss, ctx: ctx}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recoverPanic(entry, info.FullMethod, rec)
+			}
+
+			logCall(entry, info.FullMethod, err, start, shared.LogField{
+				"peer":         peerAddr(ctx),
+				"msg_sent":     stream.sent.Load(),
+				"msg_received": stream.received.Load(),
+			})
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+func UnaryClientInterceptor(l *ealogger.Logger, cfg *Config) grpc.UnaryClientInterceptor {
+	cfg = withDefaults(cfg, "grpc.client")
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx, entry := clientContext(ctx, l, cfg)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(entry, method, err, start, shared.LogField{
+			"target": cc.Target(),
+		})
+
+		return err
+	}
+}
+
+func StreamClientInterceptor(l *ealogger.Logger, cfg *Config) grpc.StreamClientInterceptor {
+	cfg = withDefaults(cfg, "grpc.client")
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx, entry := clientContext(ctx, l, cfg)
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			logCall(entry, method, err, start, shared.LogField{
+				"target": cc.Target(),
+			})
+			return nil, err
+		}
+
+		stream := &clientStream{ClientStream: cs, desc: desc, finished: make(chan struct{})}
+		stream.finish = func(err error) {
+			logCall(entry, method, err, start, shared.LogField{
+				"target":       cc.Target(),
+				"msg_sent":     stream.sent.Load(),
+				"msg_received": stream.received.Load(),
+			})
+		}
+
+		go stream.watch(ctx)
+
+		return stream, nil
+	}
+}
+
+// CodeLevel returns the level used for the record of a call that finished with code.
+func CodeLevel(code codes.Code) shared.Level {
+	switch code {
+	case codes.OK:
+		return shared.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return shared.WarnLevel
+	default:
+		return shared.ErrorLevel
+	}
+}
+
+func serverContext(ctx context.Context, l *ealogger.Logger, cfg *Config) (context.Context, *ealogger.Entry) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := firstMetadata(md, cfg.RequestIDHeader)
+	if requestID == "" {
+		requestID = cfg.NewRequestID()
+	}
+
+	fields := shared.LogField{"request_id": requestID}
+	if userAgent := firstMetadata(md, "user-agent"); userAgent != "" {
+		fields["user_agent"] = userAgent
+	}
+
+	entry := l.WithName(cfg.TraceName).Bind(fields)
+
+	ctx = ealogger.ContextWithRequestID(ctx, requestID)
+	ctx = ealogger.NewContext(ctx, entry)
+
+	return ctx, entry
+}
+
+func clientContext(ctx context.Context, l *ealogger.Logger, cfg *Config) (context.Context, *ealogger.Entry) {
+	entry := ealogger.FromContextOr(ctx, l)
+	if cfg.TraceName != "" {
+		entry.WithName(cfg.TraceName)
+	}
+
+	if requestID := ealogger.RequestIDFromContext(ctx); requestID != "" {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if firstMetadata(md, cfg.RequestIDHeader) == "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, cfg.RequestIDHeader, requestID)
+		}
+	}
+
+	return ctx, entry
+}
+
+func logCall(entry *ealogger.Entry, method string, err error, start time.Time, fields shared.LogField) {
+	code := status.Code(err)
+
+	fields["method"] = method
+	fields["code"] = code.String()
+	fields["duration"] = time.Since(start).String()
+
+	call := entry.Bind(fields)
+	if err != nil {
+		call.WithError(err)
+	}
+
+	// The record reports the interceptor rather than gRPC code calling it.
+	call.Log(shared.NewDefaultLogfDepth(1, CodeLevel(code), "%s %s", method, code))
+}
+
+func recoverPanic(entry *ealogger.Entry, method string, rec any) error {
+	entry.
+		WithError(ealogger.WithStack(fmt.Errorf("panic: %v", rec))).
+		Errorf("%s panic", method)
+
+	return status.Errorf(codes.Internal, "panic: %v", rec)
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+
+	return ""
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// withDefaults returns a copy of cfg with the unset fields filled in. A nil cfg uses traceName.
+func withDefaults(cfg *Config, traceName string) *Config {
+	c := Config{TraceName: traceName}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.RequestIDHeader == "" {
+		c.RequestIDHeader = "x-request-id"
+	}
+	if c.NewRequestID == nil {
+		c.NewRequestID = ealogger.NewRequestID
+	}
+
+	return &c
+}
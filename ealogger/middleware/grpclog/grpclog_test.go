@@ -0,0 +1,380 @@
+package grpclog
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type record struct {
+	level   shared.Level
+	message string
+	fields  shared.LogField
+	pc      uintptr
+}
+
+// captureAdapter keeps the records it receives and signals each one on logged.
+type captureAdapter struct {
+	mu      sync.Mutex
+	records []record
+	logged  chan struct{}
+}
+
+func (a *captureAdapter) Format(*shared.Log) {}
+
+func (a *captureAdapter) Log(log shared.Log) {
+	fields := make(shared.LogField, len(log.Data.Fields))
+	for key, value := range log.Data.Fields {
+		fields[key] = value
+	}
+
+	a.mu.Lock()
+	a.records = append(a.records, record{level: log.Level, message: log.Message, fields: fields, pc: log.PC})
+	a.mu.Unlock()
+
+	a.logged <- struct{}{}
+}
+
+func (a *captureAdapter) wait(t *testing.T) record {
+	t.Helper()
+
+	select {
+	case <-a.logged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no record was logged")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.records[len(a.records)-1]
+}
+
+func (a *captureAdapter) count() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.records)
+}
+
+// collectDesc describes a client-streaming method that answers once all requests are received.
+var collectDesc = grpc.StreamDesc{StreamName: "Collect", ClientStreams: true}
+
+func collectHandler(_ any, stream grpc.ServerStream) error {
+	for {
+		if err := stream.RecvMsg(new(healthpb.HealthCheckRequest)); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return stream.SendMsg(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func newTestClient(t *testing.T, adapter *captureAdapter) *grpc.ClientConn {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 16)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Collector",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    collectDesc.StreamName,
+			Handler:       collectHandler,
+			ClientStreams: true,
+		}},
+	}, struct{}{})
+
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(server.Stop)
+
+	l := ealogger.NewLogger(adapter)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(l, nil)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(l, nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	adapter := &captureAdapter{logged: make(chan struct{}, 10)}
+	conn := newTestClient(t, adapter)
+
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := adapter.wait(t)
+	if rec.fields["method"] != "/grpc.health.v1.Health/Check" || rec.fields["code"] != "OK" || rec.level != shared.InfoLevel {
+		t.Fatalf("got %+v", rec)
+	}
+}
+
+func TestStreamClientInterceptor_SingleResponse(t *testing.T) {
+	adapter := &captureAdapter{logged: make(chan struct{}, 10)}
+	conn := newTestClient(t, adapter)
+
+	stream, err := conn.NewStream(context.Background(), &collectDesc, "/test.Collector/Collect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := stream.SendMsg(&healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The call is logged on its single response, without waiting for io.EOF.
+	if err := stream.RecvMsg(new(healthpb.HealthCheckResponse)); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := adapter.wait(t)
+	if rec.fields["code"] != "OK" || rec.fields["msg_sent"] != int64(2) || rec.fields["msg_received"] != int64(1) {
+		t.Fatalf("got %+v", rec)
+	}
+}
+
+func TestStreamClientInterceptor_ContextCanceled(t *testing.T) {
+	adapter := &captureAdapter{logged: make(chan struct{}, 10)}
+	conn := newTestClient(t, adapter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The caller abandons the stream without reading it to the end.
+	cancel()
+
+	rec := adapter.wait(t)
+	if rec.fields["code"] != "Canceled" || rec.level != shared.WarnLevel {
+		t.Fatalf("got %+v", rec)
+	}
+
+	// Later reads report the cancellation without logging the call again.
+	_, _ = stream.Recv()
+	time.Sleep(10 * time.Millisecond)
+	if n := adapter.count(); n != 1 {
+		t.Fatalf("got %d records, want 1", n)
+	}
+}
+
+func TestWithDefaultsCopiesConfig(t *testing.T) {
+	cfg := &Config{TraceName: "custom"}
+	got := withDefaults(cfg, "grpc")
+
+	if cfg.RequestIDHeader != "" || cfg.NewRequestID != nil {
+		t.Fatalf("defaults were written into the caller's config: %+v", cfg)
+	}
+	if got.RequestIDHeader != "x-request-id" || got.NewRequestID == nil || got.TraceName != "custom" {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+// waitAll waits for n records and returns every record logged so far.
+func (a *captureAdapter) waitAll(t *testing.T, n int) []record {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		a.wait(t)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]record(nil), a.records...)
+}
+
+// testServiceDesc describes a service whose handlers are set by the test.
+func testServiceDesc(unary grpc.UnaryHandler, stream grpc.StreamHandler) *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: "test.Service",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Unary",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(healthpb.HealthCheckRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Service/Unary"}, unary)
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Stream",
+			Handler:       stream,
+			ClientStreams: true,
+		}},
+	}
+}
+
+func newTestServer(t *testing.T, adapter *captureAdapter, unary grpc.UnaryHandler, stream grpc.StreamHandler) *grpc.ClientConn {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 16)
+
+	l := ealogger.NewLogger(adapter)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(l, nil)),
+		grpc.StreamInterceptor(StreamServerInterceptor(l, nil)),
+	)
+	server.RegisterService(testServiceDesc(unary, stream), struct{}{})
+
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestUnaryServerInterceptor_Fields(t *testing.T) {
+	adapter := &captureAdapter{logged: make(chan struct{}, 10)}
+	conn := newTestServer(t, adapter, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "no such item")
+	}, nil)
+
+	err := conn.Invoke(context.Background(), "/test.Service/Unary", &healthpb.HealthCheckRequest{}, new(healthpb.HealthCheckResponse))
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("got %v, want NotFound", err)
+	}
+
+	rec := adapter.wait(t)
+	if rec.level != shared.WarnLevel || rec.fields["code"] != "NotFound" || rec.fields["method"] != "/test.Service/Unary" {
+		t.Fatalf("got %+v", rec)
+	}
+	if rec.fields["peer"] != "bufconn" || rec.fields["request_id"] == "" {
+		t.Fatalf("missing peer or request ID: %+v", rec)
+	}
+
+	// The record reports the interceptor, not the gRPC server code calling it.
+	frame, _ := runtime.CallersFrames([]uintptr{rec.pc}).Next()
+	if filepath.Base(frame.File) != "grpclog.go" || !strings.Contains(frame.Function, "UnaryServerInterceptor") {
+		t.Fatalf("caller = %s in %s", frame.Function, frame.File)
+	}
+}
+
+func TestUnaryServerInterceptor_RequestIDFromMetadata(t *testing.T) {
+	adapter := &captureAdapter{logged: make(chan struct{}, 10)}
+
+	var requestID string
+	conn := newTestServer(t, adapter, func(ctx context.Context, req any) (any, error) {
+		requestID = ealogger.RequestIDFromContext(ctx)
+		ealogger.FromContext(ctx).Info("handling")
+
+		return &healthpb.HealthCheckResponse{}, nil
+	}, nil)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "abc")
+	if err := conn.Invoke(ctx, "/test.Service/Unary", &healthpb.HealthCheckRequest{}, new(healthpb.HealthCheckResponse)); err != nil {
+		t.Fatal(err)
+	}
+
+	records := adapter.waitAll(t, 2)
+	if requestID != "abc" {
+		t.Fatalf("request ID in the handler context = %q, want abc", requestID)
+	}
+	for _, rec := range records {
+		if rec.fields["request_id"] != "abc" {
+			t.Fatalf("got %+v", rec)
+		}
+	}
+	if records[1].fields["code"] != "OK" || records[1].level != shared.InfoLevel {
+		t.Fatalf("got %+v", records[1])
+	}
+}
+
+func TestUnaryServerInterceptor_RecoversPanics(t *testing.T) {
+	adapter := &captureAdapter{logged: make(chan struct{}, 10)}
+	conn := newTestServer(t, adapter, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	}, nil)
+
+	err := conn.Invoke(context.Background(), "/test.Service/Unary", &healthpb.HealthCheckRequest{}, new(healthpb.HealthCheckResponse))
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("got %v, want Internal", err)
+	}
+
+	records := adapter.waitAll(t, 2)
+	if records[0].level != shared.ErrorLevel || records[0].message != "/test.Service/Unary panic" {
+		t.Fatalf("got %+v", records[0])
+	}
+	if records[1].fields["code"] != "Internal" || records[1].level != shared.ErrorLevel {
+		t.Fatalf("got %+v", records[1])
+	}
+}
+
+func TestStreamServerInterceptor_RecoversPanics(t *testing.T) {
+	adapter := &captureAdapter{logged: make(chan struct{}, 10)}
+	conn := newTestServer(t, adapter, nil, func(_ any, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(new(healthpb.HealthCheckRequest)); err != nil {
+			return err
+		}
+
+		panic("boom")
+	})
+
+	desc := &grpc.StreamDesc{StreamName: "Stream", ClientStreams: true}
+	stream, err := conn.NewStream(context.Background(), desc, "/test.Service/Stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.CloseSend()
+
+	if err := stream.RecvMsg(new(healthpb.HealthCheckResponse)); status.Code(err) != codes.Internal {
+		t.Fatalf("got %v, want Internal", err)
+	}
+
+	records := adapter.waitAll(t, 2)
+	rec := records[1]
+	if rec.fields["code"] != "Internal" || rec.fields["peer"] != "bufconn" || rec.fields["msg_received"] != int64(1) || rec.fields["msg_sent"] != int64(0) {
+		t.Fatalf("got %+v", rec)
+	}
+}
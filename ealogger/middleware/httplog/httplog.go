@@ -0,0 +1,206 @@
+package httplog
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	// RequestIDHeader is read to propagate an incoming request ID and set on the response.
+	RequestIDHeader string
+	// NewRequestID generates an ID for requests without one.
+	NewRequestID func() string
+
+	TraceName string
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+
+	return n, err
+}
+
+// ReadFrom lets io.Copy use the sendfile path of the wrapped writer, if it has one.
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := io.Copy(w.ResponseWriter, r)
+	w.bytes += int(n)
+
+	return n, err
+}
+
+// Hijack hands the connection over to the handler, e.g. for WebSockets. The access
+// record reports 101 Switching Protocols unless a status was written before.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httplog: %T does not support hijacking", w.ResponseWriter)
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+
+	return conn, buf, nil
+}
+
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// NewMiddleware returns a wrapper that stores a request-scoped Entry in the request context
+// and logs one access record per request. Panics are recovered and logged with their stack.
+// A nil cfg uses the defaults.
+func NewMiddleware(l *ealogger.Logger, cfg *Config) func(http.Handler) http.Handler {
+	cfg = withDefaults(cfg)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(cfg.RequestIDHeader)
+			if requestID == "" {
+				requestID = cfg.NewRequestID()
+			}
+			w.Header().Set(cfg.RequestIDHeader, requestID)
+
+			entry := l.WithName(cfg.TraceName).Bind(shared.LogField{"request_id": requestID})
+
+			ctx := ealogger.ContextWithRequestID(r.Context(), requestID)
+			ctx = ealogger.NewContext(ctx, entry)
+			r = r.WithContext(ctx)
+
+			rw := &responseWriter{ResponseWriter: w}
+
+			defer func() {
+				rec := recover()
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				if rec != nil && rw.status == 0 {
+					rw.WriteHeader(http.StatusInternalServerError)
+				}
+
+				access := entry.Bind(accessFields(r, rw, time.Since(start)))
+
+				if rec != nil {
+					access.
+						WithError(ealogger.WithStack(fmt.Errorf("panic: %v", rec))).
+						Errorf("%s %s panic", r.Method, r.URL.Path)
+					return
+				}
+
+				access.Log(shared.NewDefaultLogfDepth(0, StatusLevel(rw.status), "%s %s %d", r.Method, r.URL.Path, rw.status))
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+func NewDefaultMiddleware(l *ealogger.Logger) func(http.Handler) http.Handler {
+	return NewMiddleware(l, defaultConfig())
+}
+
+// StatusLevel returns the level used for the access record of a response status.
+func StatusLevel(status int) shared.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return shared.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return shared.WarnLevel
+	default:
+		return shared.InfoLevel
+	}
+}
+
+func accessFields(r *http.Request, rw *responseWriter, duration time.Duration) shared.LogField {
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	rw.status = status
+
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	return shared.LogField{
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"route":      r.Pattern,
+		"status":     status,
+		"bytes":      rw.bytes,
+		"duration":   duration.String(),
+		"remote_ip":  remoteIP,
+		"user_agent": r.UserAgent(),
+	}
+}
+
+// withDefaults returns a copy of cfg with the unset fields taken from defaultConfig.
+func withDefaults(cfg *Config) *Config {
+	defaults := defaultConfig()
+	if cfg == nil {
+		return defaults
+	}
+
+	c := *cfg
+	if c.RequestIDHeader == "" {
+		c.RequestIDHeader = defaults.RequestIDHeader
+	}
+	if c.NewRequestID == nil {
+		c.NewRequestID = defaults.NewRequestID
+	}
+
+	return &c
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		RequestIDHeader: "X-Request-ID",
+		NewRequestID:    ealogger.NewRequestID,
+		TraceName:       "http",
+	}
+}
@@ -0,0 +1,158 @@
+package httplog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+)
+
+func serve(t *testing.T, l *ealogger.Logger, handler http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	NewDefaultMiddleware(l)(handler).ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestMiddleware_CapturesStatus(t *testing.T) {
+	l, capture := newTestLogger()
+
+	serve(t, l, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(w, "short and stout")
+		w.WriteHeader(http.StatusOK)
+	}, httptest.NewRequest(http.MethodGet, "/pot", nil))
+
+	r := capture.find(t, "GET /pot")
+	if r.level != shared.WarnLevel || r.fields["status"] != http.StatusTeapot || r.fields["bytes"] != len("short and stout") {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	if file := r.callerFile(); file != "httplog.go" {
+		t.Fatalf("caller = %q, want httplog.go", file)
+	}
+}
+
+func TestMiddleware_RecoversPanics(t *testing.T) {
+	l, capture := newTestLogger()
+
+	rec := serve(t, l, func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}, httptest.NewRequest(http.MethodPost, "/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+
+	r := capture.find(t, "POST /panic panic")
+	if r.level != shared.ErrorLevel || r.err == nil || !strings.Contains(r.err.Error(), "boom") {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
+
+func TestMiddleware_PropagatesRequestID(t *testing.T) {
+	l, capture := newTestLogger()
+
+	var fromContext string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fromContext = ealogger.RequestIDFromContext(r.Context())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "abc")
+	rec := serve(t, l, handler, req)
+
+	if fromContext != "abc" || rec.Header().Get("X-Request-ID") != "abc" {
+		t.Fatalf("request ID not propagated: context %q, response %q", fromContext, rec.Header().Get("X-Request-ID"))
+	}
+	if r := capture.find(t, "GET /"); r.fields["request_id"] != "abc" {
+		t.Fatalf("request_id = %v", r.fields["request_id"])
+	}
+
+	rec = serve(t, l, handler, httptest.NewRequest(http.MethodGet, "/", nil))
+	if fromContext == "" || fromContext == "abc" || rec.Header().Get("X-Request-ID") != fromContext {
+		t.Fatalf("request ID not generated: context %q, response %q", fromContext, rec.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestMiddleware_EntryFromContext(t *testing.T) {
+	l, capture := newTestLogger()
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	serve(t, l, func(w http.ResponseWriter, r *http.Request) {
+		ealogger.FromContext(r.Context()).Info("loading users")
+	}, req)
+
+	r := capture.find(t, "loading users")
+	if r.traceName != "http" || r.fields["request_id"] != "req-1" {
+		t.Fatalf("handler log not bound to the request: %+v", r)
+	}
+}
+
+func TestMiddleware_ReadFrom(t *testing.T) {
+	l, capture := newTestLogger()
+
+	serve(t, l, func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(io.ReaderFrom); !ok {
+			t.Error("the response writer does not implement io.ReaderFrom")
+		}
+
+		// LimitReader hides the WriterTo of strings.Reader, so io.Copy uses ReadFrom.
+		_, _ = io.Copy(w, io.LimitReader(strings.NewReader("copied body"), 100))
+	}, httptest.NewRequest(http.MethodGet, "/file", nil))
+
+	r := capture.find(t, "GET /file")
+	if r.fields["status"] != http.StatusOK || r.fields["bytes"] != len("copied body") {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
+
+func TestMiddleware_Hijack(t *testing.T) {
+	l, capture := newTestLogger()
+
+	handler := NewDefaultMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		_ = buf.Flush()
+	}))
+
+	// The access record is written once the middleware returns, after the client got its response.
+	logged := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(logged)
+		handler.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, s.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	<-logged
+
+	r := capture.find(t, "GET /ws")
+	if r.fields["status"] != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
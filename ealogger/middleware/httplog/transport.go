@@ -0,0 +1,296 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type TransportConfig struct {
+	// RequestIDHeader is set on outgoing requests from the request ID in their context.
+	RequestIDHeader string
+
+	TraceName string
+
+	// MaxRetries is the number of times idempotent requests are retried after a
+	// transport error or a 502, 503 or 504 response.
+	MaxRetries int
+	RetryDelay time.Duration
+
+	// Dump enables a Trace level record with the headers and bodies of every request and response.
+	Dump bool
+	// MaxDumpBodySize limits the number of body bytes included in a dump.
+	MaxDumpBodySize int
+	// RedactHeaders lists headers whose values are replaced in dumps.
+	RedactHeaders []string
+}
+
+// Transport is an http.RoundTripper that logs outgoing requests through the Entry
+// stored in the request context, or through its Logger if there is none.
+type Transport struct {
+	base http.RoundTripper
+	l    *ealogger.Logger
+	cfg  *TransportConfig
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	entry := ealogger.FromContextOr(ctx, t.l)
+	if t.cfg.TraceName != "" {
+		entry.WithName(t.cfg.TraceName)
+	}
+
+	req = req.Clone(ctx)
+	if requestID := ealogger.RequestIDFromContext(ctx); requestID != "" && req.Header.Get(t.cfg.RequestIDHeader) == "" {
+		req.Header.Set(t.cfg.RequestIDHeader, requestID)
+	}
+
+	start := time.Now()
+
+	var (
+		resp    *http.Response
+		err     error
+		retries int
+	)
+	for {
+		if t.cfg.Dump && entry.IsEnabled(shared.TraceLevel) {
+			req.Body = t.dumpRequest(entry, req)
+		}
+
+		resp, err = t.base.RoundTrip(req)
+
+		if retries >= t.cfg.MaxRetries || !t.shouldRetry(req, resp, err) {
+			break
+		}
+
+		if req.Body != nil && req.Body != http.NoBody {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				break
+			}
+			req.Body = body
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		retries++
+
+		if !sleepContext(ctx, t.cfg.RetryDelay) {
+			resp, err = nil, ctx.Err()
+			break
+		}
+	}
+
+	fields := shared.LogField{
+		"method":   req.Method,
+		"url":      req.URL.Redacted(),
+		"duration": time.Since(start).String(),
+		"retries":  retries,
+	}
+
+	if err != nil {
+		entry.Bind(fields).WithError(err).Errorf("%s %s failed", req.Method, req.URL.Redacted())
+		return nil, err
+	}
+
+	fields["status"] = resp.StatusCode
+	if t.cfg.Dump && entry.IsEnabled(shared.TraceLevel) {
+		resp.Body = t.dumpResponse(entry, resp)
+	}
+
+	entry.Bind(fields).Log(shared.NewDefaultLogfDepth(0, StatusLevel(resp.StatusCode), "%s %s %d", req.Method, req.URL.Redacted(), resp.StatusCode))
+
+	return resp, nil
+}
+
+// sleepContext waits for d and reports whether it elapsed before ctx was done.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (t *Transport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *Transport) dumpRequest(entry *ealogger.Entry, req *http.Request) io.ReadCloser {
+	body, truncated, rest := peekBody(req.Body, t.cfg.MaxDumpBodySize)
+
+	entry.Tracef("request %s %s\n%s\n%s", req.Method, req.URL.Redacted(), t.dumpHeaders(req.Header), t.dumpBody(req.Header, body, truncated))
+
+	return rest
+}
+
+func (t *Transport) dumpResponse(entry *ealogger.Entry, resp *http.Response) io.ReadCloser {
+	body, truncated, rest := peekBody(resp.Body, t.cfg.MaxDumpBodySize)
+
+	entry.Tracef("response %s\n%s\n%s", resp.Status, t.dumpHeaders(resp.Header), t.dumpBody(resp.Header, body, truncated))
+
+	return rest
+}
+
+// dumpBody runs JSON and form bodies through the logger's redactor, so sensitive keys
+// are masked like log fields. Other bodies only get the value rules applied to the
+// whole dump message by the logger.
+func (t *Transport) dumpBody(header http.Header, body []byte, truncated bool) string {
+	suffix := ""
+	if truncated {
+		suffix = "...(truncated)"
+	}
+
+	redactor := t.l.Redactor()
+	if redactor == nil || len(body) == 0 {
+		return string(body) + suffix
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if truncated {
+			// A truncated document cannot be parsed, so its keys cannot be checked.
+			return fmt.Sprintf("(%d bytes of JSON omitted: truncated body cannot be redacted)", len(body))
+		}
+		if redacted, err := redactor.RedactJSON(body); err == nil {
+			return string(redacted)
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			return redactor.RedactForm(values).Encode() + suffix
+		}
+	}
+
+	return string(body) + suffix
+}
+
+func (t *Transport) dumpHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value := strings.Join(header[key], ", ")
+		for _, redacted := range t.cfg.RedactHeaders {
+			if strings.EqualFold(key, redacted) {
+				value = "***"
+				break
+			}
+		}
+
+		fmt.Fprintf(&b, "%s: %s\n", key, value)
+	}
+
+	return b.String()
+}
+
+// peekBody reads up to limit bytes of body and returns them together with a reader
+// that yields the complete body again.
+func peekBody(body io.ReadCloser, limit int) ([]byte, bool, io.ReadCloser) {
+	if body == nil || body == http.NoBody {
+		return nil, false, body
+	}
+
+	peeked, _ := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+
+	rest := struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(peeked), body),
+		Closer: body,
+	}
+
+	if len(peeked) > limit {
+		return peeked[:limit], true, rest
+	}
+
+	return peeked, false, rest
+}
+
+// NewTransport wraps base, or http.DefaultTransport if it is nil. A nil cfg uses the defaults.
+func NewTransport(base http.RoundTripper, l *ealogger.Logger, cfg *TransportConfig) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		base: base,
+		l:    l,
+		cfg:  withTransportDefaults(cfg),
+	}
+}
+
+func NewDefaultTransport(base http.RoundTripper, l *ealogger.Logger) *Transport {
+	return NewTransport(base, l, defaultTransportConfig())
+}
+
+// withTransportDefaults returns a copy of cfg with the unset fields taken from defaultTransportConfig.
+func withTransportDefaults(cfg *TransportConfig) *TransportConfig {
+	defaults := defaultTransportConfig()
+	if cfg == nil {
+		return defaults
+	}
+
+	c := *cfg
+	if c.RequestIDHeader == "" {
+		c.RequestIDHeader = defaults.RequestIDHeader
+	}
+	if c.MaxDumpBodySize <= 0 {
+		c.MaxDumpBodySize = defaults.MaxDumpBodySize
+	}
+	if c.RedactHeaders == nil {
+		c.RedactHeaders = defaults.RedactHeaders
+	}
+
+	return &c
+}
+
+func defaultTransportConfig() *TransportConfig {
+	return &TransportConfig{
+		RequestIDHeader: "X-Request-ID",
+		TraceName:       "http.client",
+		RetryDelay:      100 * time.Millisecond,
+		MaxDumpBodySize: 4096,
+		RedactHeaders:   []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+	}
+}
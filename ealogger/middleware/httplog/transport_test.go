@@ -0,0 +1,257 @@
+package httplog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+)
+
+type record struct {
+	level     shared.Level
+	message   string
+	traceName string
+	fields    shared.LogField
+	err       error
+	pc        uintptr
+}
+
+// captureAdapter keeps the records it receives. It accepts every level.
+type captureAdapter struct {
+	mu      sync.Mutex
+	records []record
+}
+
+func (a *captureAdapter) Format(*shared.Log) {}
+
+func (a *captureAdapter) Log(log shared.Log) {
+	fields := make(shared.LogField, len(log.Data.Fields))
+	for key, value := range log.Data.Fields {
+		fields[key] = value
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.records = append(a.records, record{
+		level:     log.Level,
+		message:   log.Message,
+		traceName: log.Data.TraceName,
+		fields:    fields,
+		err:       log.Data.Error,
+		pc:        log.PC,
+	})
+}
+
+// find returns the first record whose message starts with prefix.
+func (a *captureAdapter) find(t *testing.T, prefix string) record {
+	t.Helper()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, r := range a.records {
+		if strings.HasPrefix(r.message, prefix) {
+			return r
+		}
+	}
+
+	t.Fatalf("no record starting with %q in %d records", prefix, len(a.records))
+	return record{}
+}
+
+// callerFile returns the base name of the file a record reports as its caller.
+func (r record) callerFile() string {
+	frame, _ := runtime.CallersFrames([]uintptr{r.pc}).Next()
+
+	return filepath.Base(frame.File)
+}
+
+func newTestLogger() (*ealogger.Logger, *captureAdapter) {
+	capture := &captureAdapter{}
+
+	return ealogger.NewLogger(capture), capture
+}
+
+func TestTransport_LogsStatusAndDuration(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	l, capture := newTestLogger()
+	client := &http.Client{Transport: NewDefaultTransport(nil, l)}
+
+	resp, err := client.Get(s.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	r := capture.find(t, "GET ")
+	if r.level != shared.WarnLevel || r.traceName != "http.client" {
+		t.Fatalf("got level %s and name %q, want warn and http.client", r.level, r.traceName)
+	}
+	if r.fields["status"] != http.StatusNotFound || r.fields["method"] != http.MethodGet || r.fields["retries"] != 0 {
+		t.Fatalf("unexpected fields %v", r.fields)
+	}
+	if file := r.callerFile(); file != "transport.go" {
+		t.Fatalf("caller = %q, want transport.go", file)
+	}
+	if r.fields["url"] != s.URL+"/missing" {
+		t.Fatalf("url = %v", r.fields["url"])
+	}
+
+	duration, err := time.ParseDuration(r.fields["duration"].(string))
+	if err != nil || duration < 5*time.Millisecond {
+		t.Fatalf("duration = %v, %v", r.fields["duration"], err)
+	}
+}
+
+func TestTransport_DumpRedactsJSONAndFormBodies(t *testing.T) {
+	var received string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		_, _ = io.WriteString(w, "access_token=s3cr3t&user=bob")
+	}))
+	defer s.Close()
+
+	l, capture := newTestLogger()
+	l.SetRedactor(ealogger.NewDefaultRedactor(nil))
+	client := &http.Client{Transport: NewTransport(nil, l, &TransportConfig{Dump: true})}
+
+	const requestBody = `{"password":"hunter2","name":"alice"}`
+	req, _ := http.NewRequest(http.MethodPost, s.URL, strings.NewReader(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	// Dumping must not consume the bodies.
+	if received != requestBody || string(body) != "access_token=s3cr3t&user=bob" {
+		t.Fatalf("bodies changed: sent %q, received %q", received, body)
+	}
+
+	request := capture.find(t, "request POST")
+	if strings.Contains(request.message, "hunter2") || strings.Contains(request.message, "Bearer abc") {
+		t.Fatalf("request dump not redacted: %s", request.message)
+	}
+	if !strings.Contains(request.message, `"name": "alice"`) || !strings.Contains(request.message, "Authorization: ***") {
+		t.Fatalf("unexpected request dump: %s", request.message)
+	}
+
+	response := capture.find(t, "response 200")
+	if strings.Contains(response.message, "s3cr3t") || !strings.Contains(response.message, "user=bob") {
+		t.Fatalf("response dump not redacted: %s", response.message)
+	}
+}
+
+func TestTransport_DumpBodySizeLimit(t *testing.T) {
+	var received int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = len(body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"items":[1,2,3,4,5,6,7,8,9]}`)
+	}))
+	defer s.Close()
+
+	l, capture := newTestLogger()
+	l.SetRedactor(ealogger.NewDefaultRedactor(nil))
+	client := &http.Client{Transport: NewTransport(nil, l, &TransportConfig{Dump: true, MaxDumpBodySize: 8})}
+
+	resp, err := client.Post(s.URL, "text/plain", strings.NewReader(strings.Repeat("a", 100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if received != 100 {
+		t.Fatalf("server received %d bytes, want 100", received)
+	}
+
+	request := capture.find(t, "request POST")
+	if !strings.HasSuffix(request.message, "\n"+strings.Repeat("a", 8)+"...(truncated)") {
+		t.Fatalf("request body not truncated to 8 bytes: %q", request.message)
+	}
+
+	response := capture.find(t, "response 200")
+	if !strings.Contains(response.message, "8 bytes of JSON omitted") {
+		t.Fatalf("truncated JSON not omitted: %q", response.message)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport_CancelBetweenRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first response cancels the request, so the transport is waiting for its retry.
+	var attempts int
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		cancel()
+
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Status:     "503 Service Unavailable",
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	})
+
+	l, capture := newTestLogger()
+	transport := NewTransport(base, l, &TransportConfig{MaxRetries: 5, RetryDelay: time.Hour})
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.test/", nil)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := transport.RoundTrip(req)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the retry delay was not interrupted")
+	}
+
+	if attempts != 1 {
+		t.Fatalf("%d attempts, want 1", attempts)
+	}
+
+	r := capture.find(t, "GET ")
+	if r.level != shared.ErrorLevel || !errors.Is(r.err, context.Canceled) || r.fields["retries"] != 1 {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
@@ -0,0 +1,161 @@
+package ealogger
+
+import (
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"sort"
+	"strings"
+)
+
+// LevelSpec holds per-name level overrides. A name inherits the override of its
+// closest dotted parent, so "db" applies to "db.pool" as well.
+type LevelSpec struct {
+	// Default applies to logs whose name has no override, including unnamed logs.
+	Default shared.Level
+	Names   map[string]shared.Level
+}
+
+// ParseLevelSpec parses a comma-separated spec such as "info,db=debug,http.client=trace".
+// A bare level sets the default, which is TraceLevel if omitted.
+func ParseLevelSpec(spec string) (*LevelSpec, error) {
+	levels := &LevelSpec{
+		Default: shared.TraceLevel,
+		Names:   make(map[string]shared.Level),
+	}
+
+	for _, directive := range strings.Split(spec, ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+
+		name, levelName, named := strings.Cut(directive, "=")
+		if !named {
+			levelName = name
+		}
+
+		level, err := shared.ParseLevel(levelName)
+		if err != nil {
+			return nil, fmt.Errorf("level spec %q: %w", directive, err)
+		}
+
+		if !named {
+			levels.Default = level
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("level spec %q: empty name", directive)
+		}
+
+		levels.Names[name] = level
+	}
+
+	return levels, nil
+}
+
+// Level returns the level applying to name.
+func (s *LevelSpec) Level(name string) shared.Level {
+	for name != "" {
+		if level, ok := s.Names[name]; ok {
+			return level
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+
+	return s.Default
+}
+
+// String formats the spec in the form accepted by ParseLevelSpec.
+func (s *LevelSpec) String() string {
+	directives := []string{s.Default.String()}
+
+	names := make([]string, 0, len(s.Names))
+	for name := range s.Names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		directives = append(directives, name+"="+s.Names[name].String())
+	}
+
+	return strings.Join(directives, ",")
+}
+
+// SetLevelSpec parses spec with ParseLevelSpec and applies it to the logger.
+func (l *Logger) SetLevelSpec(spec string) error {
+	levels, err := ParseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	l.SetLevels(levels)
+
+	return nil
+}
+
+// SetLevels replaces the per-name level overrides. They are checked in addition to
+// the levels of the adapters. A nil spec removes all overrides.
+func (l *Logger) SetLevels(levels *LevelSpec) {
+	l.updateSettings(func(s *settings) {
+		s.levels = levels
+	})
+}
+
+// SetNameLevel overrides the level of name and its children.
+func (l *Logger) SetNameLevel(name string, level shared.Level) {
+	l.updateSettings(func(s *settings) {
+		levels := &LevelSpec{
+			Default: shared.TraceLevel,
+			Names:   map[string]shared.Level{name: level},
+		}
+		if s.levels != nil {
+			levels.Default = s.levels.Default
+			for key, value := range s.levels.Names {
+				if key != name {
+					levels.Names[key] = value
+				}
+			}
+		}
+
+		s.levels = levels
+	})
+}
+
+// Levels returns the current per-name level overrides, nil if there are none.
+func (l *Logger) Levels() *LevelSpec {
+	return l.snapshot().levels
+}
+
+// IsNameEnabled reports whether a log of the given level and name would be written,
+// taking both the adapters and the level overrides into account.
+func (l *Logger) IsNameEnabled(name string, level shared.Level) bool {
+	return l.snapshot().isNameEnabled(name, level)
+}
+
+// IsEnabled reports whether a log of the given level would be written under the entry's name.
+func (e *Entry) IsEnabled(level shared.Level) bool {
+	return e.l.IsNameEnabled(e.data.TraceName, level)
+}
+
+// Named returns a new entry whose name is the receiver's name extended with a dot, such as "db.pool".
+func (e *Entry) Named(name string) *Entry {
+	entry := e.Bind(nil)
+	if e.data.TraceName != "" {
+		name = e.data.TraceName + "." + name
+	}
+	entry.WithName(name)
+
+	return entry
+}
+
+func (l *Logger) Named(name string) *Entry {
+	return NewEntry(l).Named(name)
+}
@@ -0,0 +1,457 @@
+package ealogger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"io"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type RedactMode int
+
+const (
+	// RedactFull replaces the value with "***".
+	RedactFull RedactMode = iota
+	// RedactPartial keeps the last four characters of the value.
+	RedactPartial
+	// RedactHash replaces the value with a keyed HMAC-SHA256 so equal values can still be correlated.
+	// Without a hash key it falls back to RedactFull.
+	RedactHash
+)
+
+type RedactRule struct {
+	// Keys are case-insensitive glob patterns, such as "*password*", matched against field names.
+	Keys []string
+	// Pattern is matched against messages, string field values and error messages.
+	Pattern *regexp.Regexp
+	// Validate, if set, must accept a Pattern match for it to be redacted.
+	Validate func(match string) bool
+
+	Mode RedactMode
+}
+
+type RedactConfig struct {
+	Rules   []RedactRule
+	HashKey []byte
+}
+
+// Redactor masks sensitive data in logs before they reach any adapter.
+// Strings, maps with string keys, slices, errors, fmt.Stringers and LogValuers are redacted;
+// structs are not inspected and are logged as they are, so sensitive structs should
+// implement LogValuer or be logged field by field.
+type Redactor struct {
+	cfg *RedactConfig
+}
+
+// SetRedactor installs r to redact messages, fields, errors and DebugJSON output
+// of every log. A nil redactor disables redaction.
+func (l *Logger) SetRedactor(r *Redactor) {
+	l.updateSettings(func(s *settings) {
+		s.redactor = r
+	})
+}
+
+// Redactor returns the redactor installed by SetRedactor, or nil if there is none.
+func (l *Logger) Redactor() *Redactor {
+	return l.snapshot().redactor
+}
+
+func (r *Redactor) Redact(log shared.Log) shared.Log {
+	log.Message = r.RedactString(log.Message)
+
+	if log.Data == nil {
+		return log
+	}
+
+	data := *log.Data
+	data.Fields = r.redactFields(data.Fields)
+	data.TypedFields = r.redactTypedFields(data.TypedFields)
+	if data.Error != nil {
+		data.Error = r.redactError(data.Error)
+	}
+
+	log.Data = &data
+
+	return log
+}
+
+// RedactString applies the value rules to s.
+func (r *Redactor) RedactString(s string) string {
+	for _, rule := range r.cfg.Rules {
+		if rule.Pattern == nil {
+			continue
+		}
+
+		s = rule.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.Validate != nil && !rule.Validate(match) {
+				return match
+			}
+
+			return r.mask(match, rule.Mode)
+		})
+	}
+
+	return s
+}
+
+func (r *Redactor) redactValue(key string, value any) any {
+	if rule, ok := r.keyRule(key); ok {
+		return r.mask(fmt.Sprint(shared.ResolveValue(value)), rule.Mode)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return r.RedactString(v)
+	case json.Number:
+		if s := r.RedactString(string(v)); s != string(v) {
+			return s
+		}
+		return v
+	case shared.LogField:
+		return r.redactFields(v)
+	case map[string]any:
+		return map[string]any(r.redactFields(v))
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = r.redactValue("", item)
+		}
+		return items
+	case []byte:
+		return v
+	case shared.LogValuer:
+		return r.redactValue(key, shared.ResolveValue(v))
+	case error:
+		return r.redactError(v)
+	case fmt.Stringer:
+		return r.RedactString(v.String())
+	}
+
+	return r.redactReflect(value)
+}
+
+// redactReflect redacts maps with string keys, such as http.Header or url.Values, and
+// slices or arrays of any type. They are returned as map[string]any and []any, since a
+// masked value may not fit their element type. Other values are returned unchanged.
+func (r *Redactor) redactReflect(value any) any {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.IsNil() {
+			return value
+		}
+
+		redacted := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key := iter.Key().String()
+			redacted[key] = r.redactValue(key, iter.Value().Interface())
+		}
+		return redacted
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return value
+		}
+
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = r.redactValue("", v.Index(i).Interface())
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+func (r *Redactor) redactFields(fields map[string]any) shared.LogField {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	redacted := make(shared.LogField, len(fields))
+	for key, value := range fields {
+		redacted[key] = r.redactValue(key, value)
+	}
+
+	return redacted
+}
+
+// redactTypedFields returns fields unchanged when nothing has to be redacted,
+// so the caller's slice is only copied when necessary.
+func (r *Redactor) redactTypedFields(fields []shared.Field) []shared.Field {
+	var redacted []shared.Field
+
+	for i, field := range fields {
+		replacement, changed := r.redactTypedField(field)
+		if !changed {
+			if redacted != nil {
+				redacted = append(redacted, field)
+			}
+			continue
+		}
+
+		if redacted == nil {
+			redacted = make([]shared.Field, i, len(fields))
+			copy(redacted, fields[:i])
+		}
+		redacted = append(redacted, replacement)
+	}
+
+	if redacted == nil {
+		return fields
+	}
+
+	return redacted
+}
+
+func (r *Redactor) redactTypedField(field shared.Field) (shared.Field, bool) {
+	if rule, ok := r.keyRule(field.Key); ok {
+		return shared.String(field.Key, r.mask(field.Text(), rule.Mode)), true
+	}
+
+	switch field.Type {
+	case shared.StringType:
+		if s := r.RedactString(field.Str); s != field.Str {
+			return shared.String(field.Key, s), true
+		}
+	case shared.StringerType, shared.ErrorType:
+		text := field.Text()
+		if s := r.RedactString(text); s != text {
+			return shared.String(field.Key, s), true
+		}
+	case shared.AnyType, shared.ObjectType:
+		return shared.Any(field.Key, r.redactValue(field.Key, field.Value())), true
+	}
+
+	return field, false
+}
+
+func (r *Redactor) redactError(err error) error {
+	redacted := &redactedError{
+		msg:   r.RedactString(err.Error()),
+		typ:   shared.ErrorTypeName(err),
+		stack: shared.StackFromError(err),
+	}
+	if fielder, ok := err.(shared.ErrorFielder); ok {
+		redacted.fields = r.redactFields(fielder.ErrorFields())
+	}
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := wrapped.Unwrap(); inner != nil {
+			redacted.wrapped = []error{r.redactError(inner)}
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapped.Unwrap() {
+			if inner != nil {
+				redacted.wrapped = append(redacted.wrapped, r.redactError(inner))
+			}
+		}
+		return &redactedJoinError{redacted}
+	}
+
+	return redacted
+}
+
+// RedactJSON redacts the keys and values of a JSON document and returns it indented.
+// Numbers are kept exactly as written.
+func (r *Redactor) RedactJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+
+	return json.MarshalIndent(r.redactValue("", value), "", "  ")
+}
+
+// RedactForm returns a copy of form values with the values of sensitive keys masked
+// and the value rules applied to the others.
+func (r *Redactor) RedactForm(values url.Values) url.Values {
+	redacted := make(url.Values, len(values))
+	for key, items := range values {
+		rule, masked := r.keyRule(key)
+
+		redacted[key] = make([]string, len(items))
+		for i, item := range items {
+			if masked {
+				redacted[key][i] = r.mask(item, rule.Mode)
+			} else {
+				redacted[key][i] = r.RedactString(item)
+			}
+		}
+	}
+
+	return redacted
+}
+
+func (r *Redactor) keyRule(key string) (RedactRule, bool) {
+	if key == "" {
+		return RedactRule{}, false
+	}
+
+	key = strings.ToLower(key)
+	for _, rule := range r.cfg.Rules {
+		for _, pattern := range rule.Keys {
+			if matched, _ := matchKeyPattern(pattern, key); matched {
+				return rule, true
+			}
+		}
+	}
+
+	return RedactRule{}, false
+}
+
+// matchKeyPattern reports whether the lowercase key matches the case-insensitive pattern.
+func matchKeyPattern(pattern, key string) (bool, error) {
+	return path.Match(strings.ToLower(pattern), key)
+}
+
+// checkKeyPattern returns an error if pattern is malformed, using the same matching as the redactor.
+func checkKeyPattern(pattern string) error {
+	_, err := matchKeyPattern(pattern, "")
+
+	return err
+}
+
+func (r *Redactor) mask(value string, mode RedactMode) string {
+	switch mode {
+	case RedactPartial:
+		runes := []rune(value)
+		if len(runes) <= 4 {
+			return shared.SecretMask
+		}
+		return shared.SecretMask + string(runes[len(runes)-4:])
+	case RedactHash:
+		if len(r.cfg.HashKey) == 0 {
+			return shared.SecretMask
+		}
+		mac := hmac.New(sha256.New, r.cfg.HashKey)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:32]
+	default:
+		return shared.SecretMask
+	}
+}
+
+// redactedError replaces a logged error, keeping its structure with redacted messages.
+type redactedError struct {
+	msg     string
+	typ     string
+	fields  shared.LogField
+	stack   shared.Stack
+	wrapped []error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	if len(e.wrapped) == 0 {
+		return nil
+	}
+
+	return e.wrapped[0]
+}
+
+func (e *redactedError) ErrorType() string {
+	return e.typ
+}
+
+func (e *redactedError) ErrorFields() map[string]any {
+	return e.fields
+}
+
+func (e *redactedError) StackTrace() shared.Stack {
+	return e.stack
+}
+
+type redactedJoinError struct {
+	*redactedError
+}
+
+func (e *redactedJoinError) Unwrap() []error {
+	return e.wrapped
+}
+
+var (
+	jwtPattern   = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	cardPattern  = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// LuhnValid reports whether the digits of s pass the Luhn checksum used by card numbers.
+func LuhnValid(s string) bool {
+	var sum, count int
+	double := false
+
+	for i := len(s) - 1; i >= 0; i-- {
+		c := rune(s[i])
+		if !unicode.IsDigit(c) {
+			continue
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+		count++
+		double = !double
+	}
+
+	return count >= 13 && sum%10 == 0
+}
+
+// NewRedactor returns a redactor applying the rules of cfg. A nil cfg has no rules.
+func NewRedactor(cfg *RedactConfig) *Redactor {
+	if cfg == nil {
+		cfg = &RedactConfig{}
+	}
+
+	return &Redactor{cfg: cfg}
+}
+
+// NewDefaultRedactor masks common credential fields, JWTs and card numbers, and hashes
+// email addresses with hashKey.
+func NewDefaultRedactor(hashKey []byte) *Redactor {
+	return NewRedactor(defaultRedactConfig(hashKey))
+}
+
+func defaultRedactConfig(hashKey []byte) *RedactConfig {
+	return &RedactConfig{
+		HashKey: hashKey,
+		Rules: []RedactRule{
+			{
+				Keys: []string{"*password*", "*passwd*", "*secret*", "*token*", "*api_key*", "*apikey*", "authorization", "cookie", "set-cookie"},
+				Mode: RedactFull,
+			},
+			{Pattern: jwtPattern, Mode: RedactFull},
+			{Pattern: cardPattern, Validate: LuhnValid, Mode: RedactPartial},
+			{Pattern: emailPattern, Mode: RedactHash},
+		},
+	}
+}
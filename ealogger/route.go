@@ -0,0 +1,81 @@
+package ealogger
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/adapters"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"strings"
+)
+
+// RouteAdapter forwards to an adapter only the logs whose name matches its routes.
+// A name matches a route equal to it or to one of its dotted parents, see Named.
+type RouteAdapter struct {
+	adapter adapters.Adapter
+
+	names   []string
+	exclude []string
+}
+
+func (a *RouteAdapter) Log(log shared.Log) {
+	if !a.Matches(log.Data.TraceName) {
+		return
+	}
+
+	a.adapter.Log(log)
+}
+
+func (a *RouteAdapter) Format(log *shared.Log) {
+	a.adapter.Format(log)
+}
+
+func (a *RouteAdapter) IsEnabled(level shared.Level) bool {
+	enabler, ok := a.adapter.(adapters.LevelEnabler)
+
+	return !ok || enabler.IsEnabled(level)
+}
+
+func (a *RouteAdapter) Close() error {
+	if closer, ok := a.adapter.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Unwrap returns the routed adapter.
+func (a *RouteAdapter) Unwrap() adapters.Adapter {
+	return a.adapter
+}
+
+// Matches reports whether logs named name are forwarded. Without names every log
+// that is not excluded matches.
+func (a *RouteAdapter) Matches(name string) bool {
+	for _, route := range a.exclude {
+		if matchesRoute(name, route) {
+			return false
+		}
+	}
+
+	if len(a.names) == 0 {
+		return true
+	}
+
+	for _, route := range a.names {
+		if matchesRoute(name, route) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesRoute(name, route string) bool {
+	return name == route || strings.HasPrefix(name, route) && name[len(route)] == '.'
+}
+
+func NewRouteAdapter(adapter adapters.Adapter, names, exclude []string) *RouteAdapter {
+	return &RouteAdapter{
+		adapter: adapter,
+		names:   names,
+		exclude: exclude,
+	}
+}
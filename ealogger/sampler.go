@@ -0,0 +1,76 @@
+package ealogger
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"sync/atomic"
+	"time"
+)
+
+const samplerCounters = 4096
+
+// Sampler limits repeated logs. Within each tick, the first Initial logs with the same
+// level and message are written, then every Thereafter-th one; the rest are dropped.
+type Sampler struct {
+	tick       time.Duration
+	initial    uint64
+	thereafter uint64
+
+	counters [shared.UnselectedLevel - shared.TraceLevel + 1][samplerCounters]samplerCounter
+}
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// SetSampler enables sampling of logs before they reach the adapters. A nil sampler disables it.
+func (l *Logger) SetSampler(s *Sampler) {
+	l.updateSettings(func(settings *settings) {
+		settings.sampler = s
+	})
+}
+
+// Sample reports whether log should be written.
+func (s *Sampler) Sample(log shared.Log) bool {
+	if log.Level < shared.TraceLevel || log.Level > shared.UnselectedLevel {
+		return true
+	}
+
+	counter := &s.counters[log.Level-shared.TraceLevel][samplerHash(log.Message)%samplerCounters]
+
+	now := log.Time.UnixNano()
+	if log.Time.IsZero() {
+		now = time.Now().UnixNano()
+	}
+	if resetAt := counter.resetAt.Load(); now > resetAt {
+		if counter.resetAt.CompareAndSwap(resetAt, now+s.tick.Nanoseconds()) {
+			counter.count.Store(0)
+		}
+	}
+
+	n := counter.count.Add(1)
+	if n <= s.initial {
+		return true
+	}
+
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+// samplerHash is the 32-bit FNV-1a hash of s.
+func samplerHash(s string) uint32 {
+	hash := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= 16777619
+	}
+
+	return hash
+}
+
+func NewSampler(tick time.Duration, initial, thereafter int) *Sampler {
+	return &Sampler{
+		tick:       tick,
+		initial:    uint64(initial),
+		thereafter: uint64(thereafter),
+	}
+}
@@ -0,0 +1,52 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConfigError is a configuration error annotated with the path of the offending value,
+// such as "adapters[1].options.address".
+type ConfigError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors collects the errors of a validation.
+type ConfigErrors []error
+
+func (e *ConfigErrors) Add(path string, err error) {
+	if err != nil {
+		*e = append(*e, &ConfigError{Path: path, Err: err})
+	}
+}
+
+func (e *ConfigErrors) Addf(path string, format string, args ...any) {
+	e.Add(path, fmt.Errorf(format, args...))
+}
+
+// Duration parses value, adding an error if it is not a valid duration. An empty value is zero.
+func (e *ConfigErrors) Duration(path, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(value)
+	e.Add(path, err)
+
+	return duration
+}
+
+// Err returns the collected errors joined, nil if there are none.
+func (e ConfigErrors) Err() error {
+	return errors.Join(e...)
+}
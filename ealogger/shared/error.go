@@ -0,0 +1,142 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorFielder is implemented by errors that carry structured fields.
+// The fields are merged into every log record the error is attached to.
+type ErrorFielder interface {
+	ErrorFields() map[string]any
+}
+
+// ErrorTyper is implemented by errors that stand in for another error and report its type.
+type ErrorTyper interface {
+	ErrorType() string
+}
+
+// ErrorInfo is the structured representation of a logged error.
+type ErrorInfo struct {
+	Message string
+	Type    string
+
+	// Chain holds the messages of err and of every error reachable through errors.Unwrap.
+	Chain []string
+	// Errors holds the errors combined with errors.Join, if any.
+	Errors []ErrorInfo
+}
+
+func NewErrorInfo(err error) ErrorInfo {
+	info := ErrorInfo{
+		Message: err.Error(),
+		Type:    ErrorTypeName(err),
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		// Wrappers that do not change the message, such as stack annotations, are skipped.
+		if message := e.Error(); len(info.Chain) == 0 || info.Chain[len(info.Chain)-1] != message {
+			info.Chain = append(info.Chain, message)
+		}
+
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			for _, inner := range joined.Unwrap() {
+				if inner != nil {
+					info.Errors = append(info.Errors, NewErrorInfo(inner))
+				}
+			}
+		}
+	}
+
+	return info
+}
+
+// ErrorTypeName returns the type name of err, as reported by ErrorTyper if implemented.
+func ErrorTypeName(err error) string {
+	if typer, ok := err.(ErrorTyper); ok {
+		return typer.ErrorType()
+	}
+
+	return fmt.Sprintf("%T", err)
+}
+
+// Map returns the info as a map suitable for structured encoders.
+func (i ErrorInfo) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"message": i.Message,
+		"type":    i.Type,
+	}
+
+	if len(i.Chain) > 1 {
+		chain := make([]interface{}, len(i.Chain))
+		for n, message := range i.Chain {
+			chain[n] = message
+		}
+
+		m["chain"] = chain
+	}
+
+	if len(i.Errors) > 0 {
+		errs := make([]interface{}, len(i.Errors))
+		for n, inner := range i.Errors {
+			errs[n] = inner.Map()
+		}
+
+		m["errors"] = errs
+	}
+
+	return m
+}
+
+// ErrorFields collects the fields of every ErrorFielder in the tree of err.
+// Fields of outer errors take precedence over the ones they wrap.
+func ErrorFields(err error) LogField {
+	if err == nil {
+		return nil
+	}
+
+	var fields LogField
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		fields = ErrorFields(wrapped.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapped.Unwrap() {
+			for key, value := range ErrorFields(inner) {
+				if fields == nil {
+					fields = make(LogField)
+				}
+				fields[key] = value
+			}
+		}
+	}
+
+	if fielder, ok := err.(ErrorFielder); ok {
+		if fields == nil {
+			fields = make(LogField)
+		}
+		for key, value := range fielder.ErrorFields() {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
+
+// AppendError combines err with an already attached error using errors.Join.
+// Errors previously combined this way are flattened into a single list.
+func AppendError(existing, err error) error {
+	if existing == nil {
+		return err
+	}
+	if err == nil {
+		return existing
+	}
+
+	if joined, ok := existing.(interface{ Unwrap() []error }); ok {
+		errs := append([]error{}, joined.Unwrap()...)
+		return errors.Join(append(errs, err)...)
+	}
+
+	return errors.Join(existing, err)
+}
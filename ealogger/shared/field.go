@@ -0,0 +1,164 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+type FieldType uint8
+
+const (
+	AnyType FieldType = iota
+	StringType
+	IntType
+	FloatType
+	BoolType
+	DurationType
+	TimeType
+	ErrorType
+	StringerType
+	ObjectType
+)
+
+// ObjectMarshaler is implemented by types that log themselves as a group of fields.
+type ObjectMarshaler interface {
+	LogFields() []Field
+}
+
+// Field is a typed log field. Values of the common types are stored without boxing,
+// so adapters can encode them without reflection or extra allocations.
+type Field struct {
+	Key  string
+	Type FieldType
+
+	Integer   int64
+	Str       string
+	Interface any
+}
+
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, Str: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Integer: int64(value)}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Integer: value}
+}
+
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Integer: int64(math.Float64bits(value))}
+}
+
+func Bool(key string, value bool) Field {
+	var integer int64
+	if value {
+		integer = 1
+	}
+
+	return Field{Key: key, Type: BoolType, Integer: integer}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Integer: value.UnixNano(), Interface: value.Location()}
+}
+
+// Err returns a field with the key "error".
+func Err(err error) Field {
+	return Field{Key: "error", Type: ErrorType, Interface: err}
+}
+
+func Stringer(key string, value fmt.Stringer) Field {
+	return Field{Key: key, Type: StringerType, Interface: value}
+}
+
+func Object(key string, value ObjectMarshaler) Field {
+	return Field{Key: key, Type: ObjectType, Interface: value}
+}
+
+func Any(key string, value any) Field {
+	return Field{Key: key, Type: AnyType, Interface: value}
+}
+
+// Value returns the field value as its original Go type.
+// Objects are returned as a map of their fields and LogValuers are resolved.
+func (f Field) Value() any {
+	switch f.Type {
+	case StringType:
+		return f.Str
+	case IntType:
+		return f.Integer
+	case FloatType:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType:
+		return f.time()
+	case ErrorType:
+		if f.Interface == nil {
+			return nil
+		}
+		return f.Interface.(error).Error()
+	case StringerType:
+		if f.Interface == nil {
+			return nil
+		}
+		return f.Interface.(fmt.Stringer).String()
+	case ObjectType:
+		if f.Interface == nil {
+			return nil
+		}
+		return FieldsMap(f.Interface.(ObjectMarshaler).LogFields())
+	default:
+		return ResolveValue(f.Interface)
+	}
+}
+
+// Text returns the field value formatted for text output.
+func (f Field) Text() string {
+	switch f.Type {
+	case StringType:
+		return f.Str
+	case IntType:
+		return strconv.FormatInt(f.Integer, 10)
+	case FloatType:
+		return strconv.FormatFloat(math.Float64frombits(uint64(f.Integer)), 'g', -1, 64)
+	case BoolType:
+		return strconv.FormatBool(f.Integer == 1)
+	case DurationType:
+		return time.Duration(f.Integer).String()
+	case TimeType:
+		return f.time().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(f.Value())
+	}
+}
+
+func (f Field) time() time.Time {
+	t := time.Unix(0, f.Integer)
+	if location, ok := f.Interface.(*time.Location); ok && location != nil {
+		t = t.In(location)
+	}
+
+	return t
+}
+
+// FieldsMap converts typed fields to a LogField map.
+func FieldsMap(fields []Field) LogField {
+	m := make(LogField, len(fields))
+	for _, field := range fields {
+		m[field.Key] = field.Value()
+	}
+
+	return m
+}
@@ -0,0 +1,19 @@
+package shared
+
+import (
+	"os"
+	"sync"
+)
+
+var hostname = sync.OnceValue(func() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+
+	return "unknown"
+})
+
+// Hostname returns the host name reported by the kernel, "unknown" if it cannot be read.
+func Hostname() string {
+	return hostname()
+}
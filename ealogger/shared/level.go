@@ -1,15 +1,19 @@
 package shared
 
 import (
+	"fmt"
 	"github.com/charmbracelet/log"
 	"go.uber.org/zap/zapcore"
+	"log/slog"
 	"math"
+	"strings"
 )
 
 type Level int32
 
 const (
-	DebugLevel Level = iota - 2
+	TraceLevel Level = iota - 3
+	DebugLevel
 	InfoLevel
 	WarnLevel
 	ErrorLevel
@@ -24,6 +28,8 @@ func (l Level) IsEnabled(level Level) bool {
 // String returns the string representation of the level.
 func (l Level) String() string {
 	switch l {
+	case TraceLevel:
+		return "trace"
 	case DebugLevel:
 		return "debug"
 	case InfoLevel:
@@ -41,8 +47,32 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel returns the level named s, case-insensitively.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return TraceLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	case "unselected", "off":
+		return UnselectedLevel, nil
+	default:
+		return 0, fmt.Errorf("unknown level %q", s)
+	}
+}
+
 func (l Level) ToZap() zapcore.Level {
 	switch l {
+	case TraceLevel:
+		return zapcore.DebugLevel
 	case DebugLevel:
 		return zapcore.DebugLevel
 	case InfoLevel:
@@ -62,6 +92,8 @@ func (l Level) ToZap() zapcore.Level {
 
 func (l Level) ToCharmbracelet() log.Level {
 	switch l {
+	case TraceLevel:
+		return log.DebugLevel - 4
 	case DebugLevel:
 		return log.DebugLevel
 	case InfoLevel:
@@ -79,8 +111,31 @@ func (l Level) ToCharmbracelet() log.Level {
 	}
 }
 
+func (l Level) ToSlog() slog.Level {
+	switch l {
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case FatalLevel:
+		return slog.LevelError + 4
+	case UnselectedLevel:
+		return slog.LevelInfo
+	default:
+		return slog.LevelInfo
+	}
+}
+
 func (l Level) ToGraylog() int32 {
 	switch l {
+	case TraceLevel:
+		return int32(7)
 	case DebugLevel:
 		return int32(7)
 	case InfoLevel:
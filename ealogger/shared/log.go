@@ -2,21 +2,66 @@ package shared
 
 import (
 	"fmt"
+	"runtime"
+	"sync"
+	"time"
 )
 
 type LogField map[string]interface{}
 
 type LogData struct {
-	Fields    LogField
-	Error     error
-	TraceName string
-	WithName  bool
+	Fields LogField
+	// TypedFields are shared between adapters and must not be modified.
+	TypedFields []Field
+	Error       error
+	TraceName   string
+	WithName    bool
+	Stack       Stack
+	// Multiline marks a message that spans several lines on purpose, such as DebugJSON
+	// output. Adapters keep its newlines only if their SanitizePolicy allows it.
+	Multiline bool
+
+	// pooled marks data taken from logDataPool, see ReleaseLog.
+	pooled bool
+	// fieldsBuf is the reusable storage of TypedFields for pooled data.
+	fieldsBuf []Field
+}
+
+var logDataPool = sync.Pool{
+	New: func() any {
+		return new(LogData)
+	},
+}
+
+func acquireLogData() *LogData {
+	data := logDataPool.Get().(*LogData)
+	data.pooled = true
+
+	return data
+}
+
+// ReleaseLog returns the data of a log created by NewDefaultLog, NewFieldsLog or NewLogView
+// to the pool. Other logs are left untouched. The log must not be used afterwards.
+func ReleaseLog(log Log) {
+	if log.Data == nil || !log.Data.pooled {
+		return
+	}
+
+	buf := log.Data.fieldsBuf
+	clear(buf[:cap(buf)])
+
+	*log.Data = LogData{fieldsBuf: buf[:0]}
+	logDataPool.Put(log.Data)
 }
 
 type Log struct {
 	Level   Level
 	Message string
 	Data    *LogData
+
+	Time time.Time
+	// PC is the program counter of the code that emitted the log, or zero if unknown.
+	PC uintptr
 }
 
 func NewLogCopy(log Log) Log {
@@ -29,32 +74,53 @@ func NewLogCopy(log Log) Log {
 		Level:   log.Level,
 		Message: log.Message,
 		Data: &LogData{
-			Fields:    fields,
-			Error:     log.Data.Error,
-			TraceName: log.Data.TraceName,
-			WithName:  log.Data.WithName,
+			Fields:      fields,
+			TypedFields: log.Data.TypedFields,
+			Error:       log.Data.Error,
+			TraceName:   log.Data.TraceName,
+			WithName:    log.Data.WithName,
+			Stack:       log.Data.Stack,
+			Multiline:   log.Data.Multiline,
 		},
+		Time: log.Time,
+		PC:   log.PC,
 	}
 }
 
+// NewLogView returns a shallow copy of log with its own LogData taken from a pool.
+// Fields, TypedFields and Stack are shared with log and must not be modified.
+func NewLogView(log Log) Log {
+	data := acquireLogData()
+	buf := data.fieldsBuf
+	*data = *log.Data
+	data.pooled = true
+	data.fieldsBuf = buf
+
+	log.Data = data
+
+	return log
+}
+
 func NewDefaultLog(level Level, args ...interface{}) Log {
 	return Log{
 		Level:   level,
 		Message: fmt.Sprint(args...),
-		Data: &LogData{
-			Fields: make(LogField),
-		},
+		Data:    acquireLogData(),
+		Time:    time.Now(),
+		PC:      callerPC(1),
 	}
 }
 
 func NewDefaultLogn(level Level, name string, args ...interface{}) Log {
+	data := acquireLogData()
+	data.TraceName = name
+
 	return Log{
 		Level:   level,
 		Message: fmt.Sprint(args...),
-		Data: &LogData{
-			TraceName: name,
-			Fields:    make(LogField),
-		},
+		Data:    data,
+		Time:    time.Now(),
+		PC:      callerPC(1),
 	}
 }
 
@@ -62,8 +128,70 @@ func NewDefaultLogf(level Level, format string, args ...interface{}) Log {
 	return Log{
 		Level:   level,
 		Message: fmt.Sprintf(format, args...),
-		Data: &LogData{
-			Fields: make(LogField),
-		},
+		Data:    acquireLogData(),
+		Time:    time.Now(),
+		PC:      callerPC(1),
+	}
+}
+
+// NewDefaultLogDepth is NewDefaultLog for code that builds a log itself rather than in a
+// Logger or Entry method, such as middleware. The log reports the caller depth frames up
+// from the one calling NewDefaultLogDepth; 0 reports that caller.
+func NewDefaultLogDepth(depth int, level Level, args ...interface{}) Log {
+	return Log{
+		Level:   level,
+		Message: fmt.Sprint(args...),
+		Data:    acquireLogData(),
+		Time:    time.Now(),
+		PC:      callerPC(depth),
+	}
+}
+
+// NewDefaultLogfDepth is NewDefaultLogf with the caller chosen like NewDefaultLogDepth.
+func NewDefaultLogfDepth(depth int, level Level, format string, args ...interface{}) Log {
+	return Log{
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+		Data:    acquireLogData(),
+		Time:    time.Now(),
+		PC:      callerPC(depth),
+	}
+}
+
+// NewFieldsLog returns a log with typed fields. Err fields become the error of the log.
+func NewFieldsLog(level Level, message string, fields []Field) Log {
+	data := acquireLogData()
+
+	// Fields are copied into pooled storage so the caller's variadic slice does not escape.
+	for _, field := range fields {
+		if field.Type != ErrorType {
+			data.fieldsBuf = append(data.fieldsBuf, field)
+			continue
+		}
+
+		if err, ok := field.Interface.(error); ok {
+			data.Error = AppendError(data.Error, err)
+		}
+	}
+	data.TypedFields = data.fieldsBuf
+
+	return Log{
+		Level:   level,
+		Message: message,
+		Data:    data,
+		Time:    time.Now(),
+		PC:      callerPC(1),
+	}
+}
+
+// callerPC returns the program counter of the frame depth levels above the caller of a
+// log constructor. The constructors without a depth are always called directly from a
+// Logger or Entry method and pass 1, the code that called that method.
+func callerPC(depth int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(3+depth, pcs[:]) == 0 {
+		return 0
 	}
+
+	return pcs[0]
 }
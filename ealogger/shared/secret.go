@@ -0,0 +1,37 @@
+package shared
+
+import (
+	"fmt"
+)
+
+const SecretMask = "***"
+
+// Secret wraps a sensitive value so that it is always rendered as "***",
+// whether it is formatted, marshaled to JSON or written by an adapter.
+type Secret struct {
+	Value any
+}
+
+func NewSecret(value any) Secret {
+	return Secret{Value: value}
+}
+
+func (s Secret) String() string {
+	return SecretMask
+}
+
+func (s Secret) GoString() string {
+	return SecretMask
+}
+
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(SecretMask))
+}
+
+func (s Secret) LogValue() any {
+	return SecretMask
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + SecretMask + `"`), nil
+}
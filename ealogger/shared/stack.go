@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Stack is a call stack as returned by runtime.Callers.
+type Stack []uintptr
+
+// CaptureStack returns the call stack of its caller, skipping the given number of additional frames.
+func CaptureStack(skip int) Stack {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+
+	return pcs[:n]
+}
+
+// From returns the part of the stack starting at the frame with the given program counter.
+// The whole stack is returned if pc is not found.
+func (s Stack) From(pc uintptr) Stack {
+	for i, framePC := range s {
+		if framePC == pc {
+			return s[i:]
+		}
+	}
+
+	return s
+}
+
+// String formats the stack the same way the Go runtime prints goroutine traces.
+func (s Stack) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(s)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s()\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+
+		if !more {
+			break
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// StackFromError returns the innermost stack trace carried by err or any error it wraps.
+// Errors may expose it with a StackTrace() method returning Stack or, as in
+// github.com/pkg/errors, any slice of uintptr based program counters.
+func StackFromError(err error) (stack Stack) {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if s := stackFromMethod(e); len(s) > 0 {
+			stack = s
+		}
+	}
+
+	return
+}
+
+func stackFromMethod(err error) Stack {
+	if tracer, ok := err.(interface{ StackTrace() Stack }); ok {
+		return tracer.StackTrace()
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	out := method.Call(nil)[0]
+	if out.Kind() != reflect.Slice || out.Type().Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	stack := make(Stack, out.Len())
+	for i := range stack {
+		stack[i] = uintptr(out.Index(i).Uint())
+	}
+
+	return stack
+}
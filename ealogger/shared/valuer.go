@@ -0,0 +1,75 @@
+package shared
+
+// LogValuer is implemented by values that control their own log representation.
+// The Logger calls LogValue once per record, only when an adapter accepts its level,
+// so expensive values are not computed for filtered records.
+type LogValuer interface {
+	LogValue() any
+}
+
+// maxResolveDepth protects against LogValuers that return themselves.
+const maxResolveDepth = 100
+
+// ResolveValue replaces a LogValuer by the value it returns, repeatedly.
+func ResolveValue(value any) any {
+	for i := 0; i < maxResolveDepth; i++ {
+		valuer, ok := value.(LogValuer)
+		if !ok {
+			return value
+		}
+
+		value = valuer.LogValue()
+	}
+
+	return value
+}
+
+// ResolveFields returns fields with their LogValuers resolved and whether it holds any.
+// The map is only copied when it does, since it is shared with the creator of the log.
+func ResolveFields(fields LogField) (LogField, bool) {
+	var resolved LogField
+
+	for key, value := range fields {
+		if _, ok := value.(LogValuer); !ok {
+			continue
+		}
+
+		if resolved == nil {
+			resolved = make(LogField, len(fields))
+			for k, v := range fields {
+				resolved[k] = v
+			}
+		}
+		resolved[key] = ResolveValue(value)
+	}
+
+	if resolved == nil {
+		return fields, false
+	}
+
+	return resolved, true
+}
+
+// ResolveTypedFields returns fields with the LogValuers of Any fields resolved and
+// whether it holds any. The slice is only copied when it does.
+func ResolveTypedFields(fields []Field) ([]Field, bool) {
+	var resolved []Field
+
+	for i, field := range fields {
+		if _, ok := field.Interface.(LogValuer); !ok || field.Type != AnyType {
+			continue
+		}
+
+		if resolved == nil {
+			resolved = make([]Field, len(fields))
+			copy(resolved, fields)
+		}
+		resolved[i].Interface = ResolveValue(field.Interface)
+	}
+
+	if resolved == nil {
+		return fields, false
+	}
+
+	return resolved, true
+}
@@ -0,0 +1,138 @@
+package ealogger
+
+import (
+	"context"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"log/slog"
+	"strings"
+)
+
+// SlogHandler is a slog.Handler that writes records through the adapters of a Logger.
+// Attributes become fields; attributes inside groups use dot separated keys.
+type SlogHandler struct {
+	l      *Logger
+	fields shared.LogField
+	group  string
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.IsNameEnabled("", SlogLevel(level))
+}
+
+func (h *SlogHandler) Handle(_ context.Context, record slog.Record) error {
+	log := shared.Log{
+		Level:   SlogLevel(record.Level),
+		Message: record.Message,
+		Data: &shared.LogData{
+			Fields: make(shared.LogField, len(h.fields)+record.NumAttrs()),
+		},
+		Time: record.Time,
+		PC:   record.PC,
+	}
+
+	for key, value := range h.fields {
+		log.Data.Fields[key] = value
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		if err, ok := attr.Value.Resolve().Any().(error); ok && h.group == "" && (attr.Key == "err" || attr.Key == "error") {
+			log.Data.Error = shared.AppendError(log.Data.Error, err)
+			return true
+		}
+
+		addSlogAttr(log.Data.Fields, h.group, attr)
+		return true
+	})
+
+	h.l.Log(log)
+
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := h.clone()
+	for _, attr := range attrs {
+		addSlogAttr(clone.fields, clone.group, attr)
+	}
+
+	return clone
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := h.clone()
+	clone.group = joinSlogKey(h.group, name)
+
+	return clone
+}
+
+func (h *SlogHandler) clone() *SlogHandler {
+	fields := make(shared.LogField, len(h.fields))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+
+	return &SlogHandler{
+		l:      h.l,
+		fields: fields,
+		group:  h.group,
+	}
+}
+
+// SlogLevel maps a slog level onto the closest ealogger level.
+func SlogLevel(level slog.Level) shared.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return shared.TraceLevel
+	case level < slog.LevelInfo:
+		return shared.DebugLevel
+	case level < slog.LevelWarn:
+		return shared.InfoLevel
+	case level < slog.LevelError:
+		return shared.WarnLevel
+	default:
+		return shared.ErrorLevel
+	}
+}
+
+func addSlogAttr(fields shared.LogField, group string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		for _, nested := range value.Group() {
+			addSlogAttr(fields, joinSlogKey(group, attr.Key), nested)
+		}
+		return
+	}
+
+	if attr.Key == "" {
+		return
+	}
+
+	fields[joinSlogKey(group, attr.Key)] = value.Any()
+}
+
+func joinSlogKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	if key == "" {
+		return group
+	}
+
+	return strings.Join([]string{group, key}, ".")
+}
+
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{
+		l:      l,
+		fields: make(shared.LogField),
+	}
+}
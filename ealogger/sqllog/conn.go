@@ -0,0 +1,285 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+type conn struct {
+	driver.Conn
+
+	lg *logger
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newStmt(s, c.Conn, query, c.lg), nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+
+	var (
+		t   driver.Tx
+		err error
+	)
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		t, err = bc.BeginTx(ctx, opts)
+	} else {
+		t, err = beginTx(ctx, c.Conn, opts)
+	}
+
+	c.lg.log(ctx, "begin", "", nil, start, -1, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx{Tx: t, ctx: ctx, lg: c.lg}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	c.lg.log(ctx, "query", query, args, start, -1, err)
+
+	return rows, err
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := ec.ExecContext(ctx, query, args)
+	c.lg.log(ctx, "exec", query, args, start, rowsAffected(result, err), err)
+
+	return result, err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+// beginTx starts a transaction on a conn without driver.ConnBeginTx, rejecting the
+// options it cannot honour the same way database/sql does.
+func beginTx(ctx context.Context, c driver.Conn, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+
+	t, err := c.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Done() != nil {
+		select {
+		case <-ctx.Done():
+			_ = t.Rollback()
+			return nil, ctx.Err()
+		default:
+		}
+	}
+
+	return t, nil
+}
+
+type stmt struct {
+	driver.Stmt
+
+	conn  driver.Conn
+	query string
+	lg    *logger
+}
+
+// columnConverterStmt is used for statements implementing driver.ColumnConverter,
+// so database/sql keeps converting their arguments per column.
+type columnConverterStmt struct {
+	*stmt
+}
+
+func (s columnConverterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.Stmt.(driver.ColumnConverter).ColumnConverter(idx)
+}
+
+func newStmt(s driver.Stmt, c driver.Conn, query string, lg *logger) driver.Stmt {
+	wrapped := &stmt{Stmt: s, conn: c, query: query, lg: lg}
+	if _, ok := s.(driver.ColumnConverter); ok {
+		return columnConverterStmt{wrapped}
+	}
+
+	return wrapped
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var (
+		result driver.Result
+		err    error
+	)
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = ec.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(values(args))
+	}
+
+	s.lg.log(ctx, "exec", s.query, args, start, rowsAffected(result, err), err)
+
+	return result, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(values(args))
+	}
+
+	s.lg.log(ctx, "query", s.query, args, start, -1, err)
+
+	return rows, err
+}
+
+// CheckNamedValue defers to the statement, then to its conn, like database/sql does
+// for unwrapped statements. driver.ErrSkip lets database/sql fall back to the column
+// converter or the default conversion.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	if nc, ok := s.conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type tx struct {
+	driver.Tx
+
+	ctx context.Context
+	lg  *logger
+}
+
+func (t *tx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.lg.log(t.ctx, "commit", "", nil, start, -1, err)
+
+	return err
+}
+
+func (t *tx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.lg.log(t.ctx, "rollback", "", nil, start, -1, err)
+
+	return err
+}
+
+func rowsAffected(result driver.Result, err error) int64 {
+	if err != nil || result == nil {
+		return -1
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return rows
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return named
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	vals := make([]driver.Value, len(args))
+	for i, arg := range args {
+		vals[i] = arg.Value
+	}
+
+	return vals
+}
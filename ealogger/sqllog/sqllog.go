@@ -0,0 +1,192 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"time"
+)
+
+type Config struct {
+	// Level is used for successful operations faster than SlowThreshold. The zero Level,
+	// which is WarnLevel, is taken as unset and means DebugLevel; use SlowThreshold to
+	// have operations logged as warnings.
+	Level shared.Level
+	// SlowThreshold promotes operations taking at least this long to Warn. Zero disables it.
+	SlowThreshold time.Duration
+	// RedactArgs omits argument values from records, keeping only their count.
+	RedactArgs bool
+
+	TraceName string
+}
+
+type logger struct {
+	l   *ealogger.Logger
+	cfg *Config
+}
+
+func (lg *logger) log(ctx context.Context, op, query string, args []driver.NamedValue, start time.Time, rows int64, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	duration := time.Since(start)
+
+	level := lg.cfg.Level
+	switch {
+	case err != nil:
+		level = shared.ErrorLevel
+	case lg.cfg.SlowThreshold > 0 && duration >= lg.cfg.SlowThreshold:
+		level = shared.WarnLevel
+	}
+
+	if !lg.l.IsEnabled(level) {
+		return
+	}
+
+	entry := ealogger.FromContextOr(ctx, lg.l)
+	if lg.cfg.TraceName != "" {
+		entry.WithName(lg.cfg.TraceName)
+	}
+	if !entry.IsEnabled(level) {
+		return
+	}
+
+	fields := shared.LogField{
+		"operation": op,
+		"duration":  duration.String(),
+	}
+
+	if query != "" {
+		fields["query"] = query
+		fields["arg_count"] = len(args)
+
+		if !lg.cfg.RedactArgs && len(args) > 0 {
+			values := make([]any, len(args))
+			for i, arg := range args {
+				values[i] = arg.Value
+			}
+			fields["args"] = values
+		}
+	}
+
+	if rows >= 0 {
+		fields["rows_affected"] = rows
+	}
+
+	entry.WithFields(fields)
+	if err != nil {
+		entry.WithError(err)
+	}
+
+	// The record reports the wrapped driver method rather than database/sql.
+	entry.Log(shared.NewDefaultLogDepth(1, level, op))
+}
+
+type loggingDriver struct {
+	driver.Driver
+
+	lg *logger
+}
+
+func (d *loggingDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{Conn: c, lg: d.lg}, nil
+}
+
+func (d *loggingDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &connector{Connector: c, driver: d, lg: d.lg}, nil
+	}
+
+	return &connector{Connector: dsnConnector{name: name, driver: d.Driver}, driver: d, lg: d.lg}, nil
+}
+
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type connector struct {
+	driver.Connector
+
+	driver driver.Driver
+	lg     *logger
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{Conn: cn, lg: c.lg}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Wrap returns a driver that logs the queries, statements and transactions of d.
+func Wrap(d driver.Driver, l *ealogger.Logger, cfg *Config) driver.Driver {
+	return &loggingDriver{Driver: d, lg: newLogger(l, cfg)}
+}
+
+// WrapConnector returns a connector that logs the queries, statements and transactions of c.
+func WrapConnector(c driver.Connector, l *ealogger.Logger, cfg *Config) driver.Connector {
+	lg := newLogger(l, cfg)
+
+	return &connector{
+		Connector: c,
+		driver:    &loggingDriver{Driver: c.Driver(), lg: lg},
+		lg:        lg,
+	}
+}
+
+// OpenDB is a shortcut for sql.OpenDB(WrapConnector(c, l, cfg)).
+func OpenDB(c driver.Connector, l *ealogger.Logger, cfg *Config) *sql.DB {
+	return sql.OpenDB(WrapConnector(c, l, cfg))
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Level:         shared.DebugLevel,
+		SlowThreshold: 200 * time.Millisecond,
+		RedactArgs:    true,
+		TraceName:     "sql",
+	}
+}
+
+func newLogger(l *ealogger.Logger, cfg *Config) *logger {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+
+	if cfg.Level == 0 {
+		copied := *cfg
+		copied.Level = shared.DebugLevel
+		cfg = &copied
+	}
+
+	return &logger{l: l, cfg: cfg}
+}
@@ -0,0 +1,240 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eris-apple/ealogger/ealogger"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+)
+
+// fakeDriver opens fakeConns that record the arguments of executed statements.
+type fakeDriver struct {
+	conn func() driver.Conn
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return d.conn(), nil
+}
+
+type fakeConn struct {
+	mu   sync.Mutex
+	args [][]driver.Value
+
+	columnConverter driver.ValueConverter
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	s := &fakeStmt{conn: c}
+	if c.columnConverter != nil {
+		return &fakeColumnConverterStmt{fakeStmt: s, converter: c.columnConverter}, nil
+	}
+
+	return s, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) lastArgs() []driver.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.args[len(c.args)-1]
+}
+
+// pointConn converts point arguments, which database/sql rejects by default.
+type pointConn struct {
+	*fakeConn
+}
+
+func (c pointConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if p, ok := nv.Value.(point); ok {
+		nv.Value = fmt.Sprintf("%d,%d", p.x, p.y)
+		return nil
+	}
+
+	return driver.ErrSkip
+}
+
+type point struct{ x, y int }
+
+type fakeStmt struct {
+	conn *fakeConn
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.mu.Lock()
+	s.conn.args = append(s.conn.args, args)
+	s.conn.mu.Unlock()
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+type fakeColumnConverterStmt struct {
+	*fakeStmt
+
+	converter driver.ValueConverter
+}
+
+func (s *fakeColumnConverterStmt) ColumnConverter(int) driver.ValueConverter {
+	return s.converter
+}
+
+// prefixConverter turns every argument into a string with a prefix.
+type prefixConverter string
+
+func (c prefixConverter) ConvertValue(v any) (driver.Value, error) {
+	return fmt.Sprintf("%s%v", c, v), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return nil }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+type captureAdapter struct {
+	mu      sync.Mutex
+	records []shared.LogField
+	levels  []shared.Level
+}
+
+func (a *captureAdapter) Format(*shared.Log) {}
+
+func (a *captureAdapter) Log(log shared.Log) {
+	fields := make(shared.LogField, len(log.Data.Fields))
+	for key, value := range log.Data.Fields {
+		fields[key] = value
+	}
+
+	a.mu.Lock()
+	a.records = append(a.records, fields)
+	a.levels = append(a.levels, log.Level)
+	a.mu.Unlock()
+}
+
+func openTestDB(t *testing.T, c driver.Conn, adapter *captureAdapter) *sql.DB {
+	t.Helper()
+
+	db := sql.OpenDB(WrapConnector(dsnConnector{driver: fakeDriver{conn: func() driver.Conn { return c }}}, ealogger.NewLogger(adapter), nil))
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func TestStmtUsesConnNamedValueChecker(t *testing.T) {
+	c := &fakeConn{}
+	db := openTestDB(t, pointConn{c}, &captureAdapter{})
+
+	if _, err := db.Exec("INSERT INTO points VALUES (?)", point{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.lastArgs(); len(got) != 1 || got[0] != "1,2" {
+		t.Fatalf("args = %v, want [1,2]", got)
+	}
+}
+
+func TestStmtKeepsColumnConverter(t *testing.T) {
+	c := &fakeConn{columnConverter: prefixConverter("col:")}
+	db := openTestDB(t, c, &captureAdapter{})
+
+	if _, err := db.Exec("INSERT INTO items VALUES (?)", 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.lastArgs(); len(got) != 1 || got[0] != "col:7" {
+		t.Fatalf("args = %v, want [col:7]", got)
+	}
+}
+
+func TestBeginTxRejectsUnsupportedOptions(t *testing.T) {
+	db := openTestDB(t, &fakeConn{}, &captureAdapter{})
+
+	for _, opts := range []*sql.TxOptions{{ReadOnly: true}, {Isolation: sql.LevelSerializable}} {
+		if tx, err := db.BeginTx(context.Background(), opts); err == nil {
+			_ = tx.Rollback()
+			t.Fatalf("BeginTx(%+v) succeeded on a driver without ConnBeginTx", opts)
+		}
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogsStatements(t *testing.T) {
+	adapter := &captureAdapter{}
+	db := openTestDB(t, &fakeConn{}, adapter)
+
+	if _, err := db.Exec("DELETE FROM items WHERE id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+
+	var exec shared.LogField
+	for _, fields := range adapter.records {
+		if fields["operation"] == "exec" {
+			exec = fields
+		}
+	}
+
+	if exec == nil {
+		t.Fatalf("no exec record in %v", adapter.records)
+	}
+	if exec["query"] != "DELETE FROM items WHERE id = ?" || exec["rows_affected"] != int64(1) || exec["arg_count"] != 1 {
+		t.Fatalf("got %v", exec)
+	}
+	if _, ok := exec["args"]; ok {
+		t.Fatalf("args are logged although RedactArgs is set: %v", exec)
+	}
+}
+
+func TestSlowThreshold(t *testing.T) {
+	adapter := &captureAdapter{}
+	lg := newLogger(ealogger.NewLogger(adapter), &Config{SlowThreshold: 100 * time.Millisecond})
+
+	lg.log(context.Background(), "exec", "SELECT 1", nil, time.Now(), -1, nil)
+	lg.log(context.Background(), "exec", "SELECT pg_sleep(1)", nil, time.Now().Add(-time.Second), -1, nil)
+	lg.log(context.Background(), "exec", "SELECT broken", nil, time.Now(), -1, fmt.Errorf("syntax error"))
+
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+
+	// The unset Level logs fast operations at Debug rather than at the zero WarnLevel.
+	want := []shared.Level{shared.DebugLevel, shared.WarnLevel, shared.ErrorLevel}
+	if len(adapter.levels) != len(want) {
+		t.Fatalf("got %d records, want %d", len(adapter.levels), len(want))
+	}
+	for i, level := range want {
+		if adapter.levels[i] != level {
+			t.Fatalf("record %d (%v) has level %s, want %s", i, adapter.records[i]["query"], adapter.levels[i], level)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package ealogger
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/shared"
+)
+
+type LogValuer = shared.LogValuer
+
+type lazyValue func() any
+
+func (f lazyValue) LogValue() any {
+	return f()
+}
+
+// Lazy returns a value that is computed by f only when an adapter writes the log.
+func Lazy(f func() any) LogValuer {
+	return lazyValue(f)
+}
@@ -0,0 +1,152 @@
+package ealogger
+
+import (
+	"bytes"
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+var linePrefixLevels = map[string]shared.Level{
+	"TRACE":   shared.TraceLevel,
+	"DEBUG":   shared.DebugLevel,
+	"INFO":    shared.InfoLevel,
+	"WARN":    shared.WarnLevel,
+	"WARNING": shared.WarnLevel,
+	"ERROR":   shared.ErrorLevel,
+}
+
+// LineWriter is an io.Writer that logs every written line as a separate record.
+type LineWriter struct {
+	l     *Logger
+	level shared.Level
+
+	parseLevels bool
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.logLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush logs the buffered text that is not terminated by a newline yet.
+func (w *LineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+}
+
+// ParseLevels enables picking the level of a line from a leading prefix such as "[WARN]".
+// The prefix is removed from the message.
+func (w *LineWriter) ParseLevels() *LineWriter {
+	w.parseLevels = true
+
+	return w
+}
+
+func (w *LineWriter) logLine(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" {
+		return
+	}
+
+	level := w.level
+	if w.parseLevels {
+		level, line = parseLinePrefix(line, level)
+	}
+
+	w.l.Log(shared.Log{
+		Level:   level,
+		Message: line,
+		Data: &shared.LogData{
+			Fields: make(shared.LogField),
+		},
+		Time: time.Now(),
+	})
+}
+
+func parseLinePrefix(line string, level shared.Level) (shared.Level, string) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, "[") {
+		return level, line
+	}
+
+	end := strings.IndexByte(trimmed, ']')
+	if end < 0 {
+		return level, line
+	}
+
+	prefixLevel, ok := linePrefixLevels[strings.ToUpper(trimmed[1:end])]
+	if !ok {
+		return level, line
+	}
+
+	return prefixLevel, strings.TrimLeft(trimmed[end+1:], " \t")
+}
+
+// Writer returns an io.Writer that logs every written line at the given level.
+func (l *Logger) Writer(level shared.Level) *LineWriter {
+	return &LineWriter{
+		l:     l,
+		level: level,
+	}
+}
+
+// StdLogger returns a standard library logger that writes through l at the given level.
+// Level prefixes are not parsed; use l.Writer(level).ParseLevels().StdLogger() to opt in.
+func (l *Logger) StdLogger(level shared.Level) *log.Logger {
+	return l.Writer(level).StdLogger()
+}
+
+// RedirectStdLog sends the output of the standard library's global logger through l
+// at the given level. The returned function restores the previous configuration.
+// Level prefixes are not parsed; use l.Writer(level).ParseLevels().RedirectStdLog() to opt in.
+func (l *Logger) RedirectStdLog(level shared.Level) func() {
+	return l.Writer(level).RedirectStdLog()
+}
+
+// StdLogger returns a standard library logger that writes through w.
+func (w *LineWriter) StdLogger() *log.Logger {
+	return log.New(w, "", 0)
+}
+
+// RedirectStdLog sends the output of the standard library's global logger through w.
+// The returned function restores the previous configuration.
+func (w *LineWriter) RedirectStdLog() func() {
+	flags := log.Flags()
+	prefix := log.Prefix()
+	output := log.Writer()
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(w)
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(output)
+	}
+}
@@ -0,0 +1,130 @@
+package ealogger
+
+import (
+	"github.com/eris-apple/ealogger/ealogger/shared"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapCore is a zapcore.Core that writes entries through the adapters of a Logger,
+// so libraries that accept a *zap.Logger log through ealogger.
+// Named loggers become the trace name and zap.Error fields the attached error.
+type ZapCore struct {
+	l      *Logger
+	fields shared.LogField
+	err    error
+}
+
+// Enabled reports whether any logger name may write logs of the given level.
+// Check applies the level overrides of the entry's logger name.
+func (c *ZapCore) Enabled(level zapcore.Level) bool {
+	return c.l.IsEnabled(ZapLevel(level))
+}
+
+func (c *ZapCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := c.clone()
+	clone.err = addZapFields(clone.fields, clone.err, fields)
+
+	return clone
+}
+
+func (c *ZapCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.l.IsNameEnabled(entry.LoggerName, ZapLevel(entry.Level)) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *ZapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	log := shared.Log{
+		Level:   ZapLevel(entry.Level),
+		Message: entry.Message,
+		Data: &shared.LogData{
+			Fields:    make(shared.LogField, len(c.fields)+len(fields)),
+			Error:     c.err,
+			TraceName: entry.LoggerName,
+			WithName:  entry.LoggerName != "",
+		},
+		Time: entry.Time,
+	}
+
+	for key, value := range c.fields {
+		log.Data.Fields[key] = value
+	}
+	log.Data.Error = addZapFields(log.Data.Fields, log.Data.Error, fields)
+
+	if entry.Caller.Defined {
+		log.PC = entry.Caller.PC
+	}
+
+	if entry.Stack != "" {
+		log.Data.Fields["stacktrace"] = entry.Stack
+	}
+
+	c.l.Log(log)
+
+	return nil
+}
+
+func (c *ZapCore) Sync() error {
+	return nil
+}
+
+func (c *ZapCore) clone() *ZapCore {
+	fields := make(shared.LogField, len(c.fields))
+	for key, value := range c.fields {
+		fields[key] = value
+	}
+
+	return &ZapCore{
+		l:      c.l,
+		fields: fields,
+		err:    c.err,
+	}
+}
+
+// ZapLevel maps a zap level onto the closest ealogger level.
+func ZapLevel(level zapcore.Level) shared.Level {
+	switch {
+	case level < zapcore.InfoLevel:
+		return shared.DebugLevel
+	case level == zapcore.InfoLevel:
+		return shared.InfoLevel
+	case level == zapcore.WarnLevel:
+		return shared.WarnLevel
+	case level < zapcore.FatalLevel:
+		return shared.ErrorLevel
+	default:
+		return shared.FatalLevel
+	}
+}
+
+// addZapFields encodes zap fields into fields and returns err combined with the errors
+// carried by zap.Error fields.
+func addZapFields(fields shared.LogField, err error, zapFields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+
+	for _, field := range zapFields {
+		if field.Type == zapcore.ErrorType && field.Key == "error" {
+			if fieldErr, ok := field.Interface.(error); ok {
+				err = shared.AppendError(err, fieldErr)
+				continue
+			}
+		}
+
+		field.AddTo(enc)
+	}
+
+	for key, value := range enc.Fields {
+		fields[key] = value
+	}
+
+	return err
+}
+
+func NewZapCore(l *Logger) *ZapCore {
+	return &ZapCore{
+		l:      l,
+		fields: make(shared.LogField),
+	}
+}